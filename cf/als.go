@@ -0,0 +1,154 @@
+package cf
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// ALS is matrix factorization by alternating least squares with weighted-λ regularization (Zhou et al.,
+// 2008). Every rating r of movie i by user u is approximated by mean + q_i · p_u. Each iteration holds the
+// movie factors fixed and solves for every user's factors exactly, then does the same for the movies. The
+// penalty on a user's or movie's factors is Regularization times its number of ratings, so users and movies
+// with many ratings are not over-regularized.
+//
+// The least-squares solves of an iteration are independent and run on one goroutine per CPU. Each writes
+// only its own factors, so the result depends on nothing but Seed, which sets the initial factors.
+type ALS struct {
+	Factors        int     // Number of latent factors
+	Iterations     int     // Number of times the user and the movie factors are each solved for
+	Regularization float64 // λ; the penalty on a factor vector is λ times its number of ratings
+	InitStd        float64 // Standard deviation of the normally distributed initial factors
+	Seed           int64
+
+	model *factorModel
+}
+
+// NewALS returns an ALS with 20 factors solved for 15 iterations with λ = 0.2
+func NewALS() *ALS {
+	return &ALS{Factors: 20, Iterations: 15, Regularization: 0.2, InitStd: 0.1}
+}
+
+// alsParamNames are the parameters alsParams reads
+var alsParamNames = []string{"factors", "iterations", "regularization", "init-std", "seed"}
+
+// newALS returns a constructor for ALS predictors that start out as a copy of def
+func newALS(def ALS) func(params Params) (Predictor, error) {
+	return func(params Params) (Predictor, error) {
+		if err := params.check(alsParamNames...); err != nil {
+			return nil, err
+		}
+
+		p := def
+		if err := p.alsParams(params); err != nil {
+			return nil, err
+		}
+		return &p, nil
+	}
+}
+
+// alsParams applies the parameters shared by the alternating least squares factorizations
+func (p *ALS) alsParams(params Params) error {
+	var err error
+	if p.Factors, err = params.Int("factors", p.Factors); err != nil {
+		return err
+	}
+	if p.Iterations, err = params.Int("iterations", p.Iterations); err != nil {
+		return err
+	}
+	if p.Regularization, err = params.Float("regularization", p.Regularization); err != nil {
+		return err
+	}
+	if p.InitStd, err = params.Float("init-std", p.InitStd); err != nil {
+		return err
+	}
+	seed, err := params.Int("seed", int(p.Seed))
+	p.Seed = int64(seed)
+	return err
+}
+
+// validate checks the training settings
+func (p *ALS) validate() error {
+	if p.Factors < 1 {
+		return fmt.Errorf("factors must be at least 1, got %d", p.Factors)
+	}
+	if p.Iterations < 0 || p.Regularization <= 0 || p.InitStd < 0 {
+		return fmt.Errorf("iterations and init-std must not be negative and regularization must be positive")
+	}
+	return nil
+}
+
+// Fit implements Predictor
+func (p *ALS) Fit(ratings *Ratings) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+
+	m := newFactorModel(ratings, p.Factors, p.InitStd, rand.New(rand.NewSource(p.Seed)))
+
+	var failed error
+	var failedOnce sync.Once
+	solve := func(entries []Entry, others [][]float64, result []float64) {
+		if err := p.solve(entries, others, m.mean, result); err != nil {
+			failedOnce.Do(func() { failed = err })
+		}
+	}
+
+	for iteration := 0; iteration < p.Iterations; iteration++ {
+		forEachRow(ratings.NumUsers(), func(user int) {
+			solve(ratings.UserRatings(user), m.itemFactors, m.userFactors[user])
+		})
+		forEachRow(ratings.NumItems(), func(item int) {
+			solve(ratings.ItemRatings(item), m.userFactors, m.itemFactors[item])
+		})
+		if failed != nil {
+			return failed
+		}
+	}
+
+	p.model = m
+	return nil
+}
+
+// solve sets result to the factors that best fit the ratings in entries, less mean, given the fixed factors
+// of the other side: the solution of (summation( q · qᵀ ) + λ · n · I) · result = summation( (r - mean) · q ).
+// Users or movies without ratings get factors of 0.
+func (p *ALS) solve(entries []Entry, others [][]float64, mean float64, result []float64) error {
+	n := len(result)
+	for factor := range result {
+		result[factor] = 0
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	a := make([]float64, n*n) // Represents: summation( q · qᵀ ) + λ · n · I
+	for _, entry := range entries {
+		other := others[entry.Index]
+		for row := 0; row < n; row++ {
+			for col := 0; col <= row; col++ {
+				a[row*n+col] += other[row] * other[col]
+			}
+			result[row] += (entry.Value - mean) * other[row]
+		}
+	}
+	for row := 0; row < n; row++ {
+		a[row*n+row] += p.Regularization * float64(len(entries))
+		for col := 0; col < row; col++ {
+			a[col*n+row] = a[row*n+col]
+		}
+	}
+
+	return solveSymmetric(a, result, n)
+}
+
+// Predict implements Predictor. Users and movies with no ratings are predicted the mean, and the prediction
+// is not OK.
+func (p *ALS) Predict(user, item int) Prediction {
+	return p.model.predict(user, item, p.model.userFactorsOf(user))
+}
+
+// PredictBatch implements Predictor
+func (p *ALS) PredictBatch(pairs []Pair) []Prediction {
+	return predictBatch(p, pairs)
+}
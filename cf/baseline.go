@@ -0,0 +1,248 @@
+package cf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BaselineKind selects what a Baseline estimates ratings from
+type BaselineKind int
+
+const (
+	// NoBaseline is the zero value, which leaves a KNN predictor's fallback and offsets as they are
+	NoBaseline BaselineKind = iota
+
+	// GlobalMean predicts the average of every rating
+	GlobalMean
+
+	// UserMean predicts the user's average rating
+	UserMean
+
+	// ItemMean predicts the movie's average rating
+	ItemMean
+
+	// UserItemBias predicts mean + b_u + b_i, where the user's and the movie's biases are fitted together
+	// with regularization, by alternating least squares or gradient descent
+	UserItemBias
+)
+
+var baselineKindNames = []string{"none", "global-mean", "user-mean", "item-mean", "bias"}
+
+// String returns the baseline kind's name
+func (k BaselineKind) String() string {
+	if k < 0 || int(k) >= len(baselineKindNames) {
+		return fmt.Sprintf("BaselineKind(%d)", int(k))
+	}
+	return baselineKindNames[k]
+}
+
+// ParseBaselineKind returns the baseline kind with the given name: "none", "global-mean", "user-mean",
+// "item-mean" or "bias"
+func ParseBaselineKind(name string) (BaselineKind, error) {
+	for idx, kindName := range baselineKindNames {
+		if name == kindName {
+			return BaselineKind(idx), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown baseline %q (available: %s)", name, strings.Join(baselineKindNames, ", "))
+}
+
+// Baseline estimates ratings from averages and biases alone. It is an algorithm of its own and, in a KNN
+// predictor, the fallback for predictions without neighbours and the offset neighbours' ratings are
+// centered on.
+//
+// Every estimate is mean + b_u + b_i, where the biases the kind does not use are 0. The user mean is the
+// damped mean mean + summation( r - mean ) / (n + UserDamping), which is the plain average when UserDamping
+// is 0 and is pulled towards the global mean for users with few ratings; the movie mean is damped the same
+// way by ItemDamping. The UserItemBias kind uses both dampings as the regularization of its least squares
+// solves, or Regularization when it is fitted by gradient descent.
+type Baseline struct {
+	Kind BaselineKind
+
+	UserDamping float64
+	ItemDamping float64
+
+	SGD            bool    // Fit UserItemBias by stochastic gradient descent instead of alternating least squares
+	Epochs         int     // Number of least squares iterations or gradient descent passes for UserItemBias
+	LearningRate   float64 // Step size of gradient descent
+	Regularization float64 // Penalty on the size of the biases in gradient descent
+
+	ratings  *Ratings
+	mean     float64
+	userBias []float64
+	itemBias []float64
+}
+
+// NewBaseline returns a baseline of the given kind. The means are not damped; the biases are fitted by 10
+// iterations of alternating least squares with the user and movie dampings of 15 and 10 from Koren's
+// "Factor in the Neighbors", or by 20 passes of gradient descent with a learning rate of 0.005 and
+// regularization of 0.02.
+func NewBaseline(kind BaselineKind) *Baseline {
+	b := &Baseline{Kind: kind, Epochs: 10, LearningRate: 0.005, Regularization: 0.02}
+	if kind == UserItemBias {
+		b.UserDamping, b.ItemDamping = 15, 10
+	}
+	return b
+}
+
+// baselineParamNames are the parameters newBaseline reads
+var baselineParamNames = []string{"user-damping", "item-damping", "solver", "epochs", "learning-rate", "regularization"}
+
+// newBaseline returns a constructor for baselines that start out as a copy of def
+func newBaseline(def Baseline) func(params Params) (Predictor, error) {
+	return func(params Params) (Predictor, error) {
+		if err := params.check(baselineParamNames...); err != nil {
+			return nil, err
+		}
+
+		b := def
+		if err := b.params(params); err != nil {
+			return nil, err
+		}
+		return &b, nil
+	}
+}
+
+// params applies the baseline's parameters
+func (b *Baseline) params(params Params) error {
+	var err error
+	if b.UserDamping, err = params.Float("user-damping", b.UserDamping); err != nil {
+		return err
+	}
+	if b.ItemDamping, err = params.Float("item-damping", b.ItemDamping); err != nil {
+		return err
+	}
+	switch solver := params.String("solver", "als"); solver {
+	case "als":
+	case "sgd":
+		b.SGD = true
+		if _, ok := params["epochs"]; !ok {
+			b.Epochs = 20
+		}
+	default:
+		return fmt.Errorf("unknown solver %q (available: als, sgd)", solver)
+	}
+	if b.Epochs, err = params.Int("epochs", b.Epochs); err != nil {
+		return err
+	}
+	if b.LearningRate, err = params.Float("learning-rate", b.LearningRate); err != nil {
+		return err
+	}
+	if b.Regularization, err = params.Float("regularization", b.Regularization); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Fit implements Predictor
+func (b *Baseline) Fit(ratings *Ratings) error {
+	if b.Kind <= NoBaseline || int(b.Kind) >= len(baselineKindNames) {
+		return fmt.Errorf("baseline kind %v cannot be fitted", b.Kind)
+	}
+	if b.UserDamping < 0 || b.ItemDamping < 0 || b.Epochs < 0 || b.Regularization < 0 || (b.SGD && b.LearningRate <= 0) {
+		return fmt.Errorf("dampings, epochs and regularization must not be negative and the learning rate must be positive")
+	}
+
+	b.ratings = ratings
+	b.mean = globalMean(ratings)
+	b.userBias = make([]float64, ratings.NumUsers())
+	b.itemBias = make([]float64, ratings.NumItems())
+
+	switch {
+	case b.Kind == UserMean:
+		b.solveUsers()
+	case b.Kind == ItemMean:
+		b.solveItems()
+	case b.Kind == UserItemBias && b.SGD:
+		b.fitSGD()
+	case b.Kind == UserItemBias:
+		// Movie biases first, as in Koren's paper
+		for epoch := 0; epoch < b.Epochs; epoch++ {
+			b.solveItems()
+			b.solveUsers()
+		}
+	}
+
+	return nil
+}
+
+// solveItems sets every movie's bias to the damped average of its ratings less the mean and user biases
+func (b *Baseline) solveItems() {
+	for item := range b.itemBias {
+		var sumOfResiduals float64 = 0 // Represents: summation( Rating - mean - b_u )
+		entries := b.ratings.ItemRatings(item)
+		for _, entry := range entries {
+			sumOfResiduals += entry.Value - b.mean - b.userBias[entry.Index]
+		}
+		if len(entries) > 0 {
+			b.itemBias[item] = sumOfResiduals / (b.ItemDamping + float64(len(entries)))
+		}
+	}
+}
+
+// solveUsers sets every user's bias to the damped average of their ratings less the mean and movie biases
+func (b *Baseline) solveUsers() {
+	for user := range b.userBias {
+		var sumOfResiduals float64 = 0 // Represents: summation( Rating - mean - b_i )
+		entries := b.ratings.UserRatings(user)
+		for _, entry := range entries {
+			sumOfResiduals += entry.Value - b.mean - b.itemBias[entry.Index]
+		}
+		if len(entries) > 0 {
+			b.userBias[user] = sumOfResiduals / (b.UserDamping + float64(len(entries)))
+		}
+	}
+}
+
+// fitSGD fits the user and movie biases by gradient descent, visiting the ratings in the same order every pass
+func (b *Baseline) fitSGD() {
+	all := b.ratings.All()
+	for epoch := 0; epoch < b.Epochs; epoch++ {
+		for _, rating := range all {
+			residual := rating.Value - b.estimate(rating.User, rating.Item)
+			b.userBias[rating.User] += b.LearningRate * (residual - b.Regularization*b.userBias[rating.User])
+			b.itemBias[rating.Item] += b.LearningRate * (residual - b.Regularization*b.itemBias[rating.Item])
+		}
+	}
+}
+
+// estimate returns mean + b_u + b_i, leaving out the bias of a user or movie the baseline does not know
+func (b *Baseline) estimate(user, item int) float64 {
+	estimate := b.mean
+	if user >= 0 && user < len(b.userBias) {
+		estimate += b.userBias[user]
+	}
+	if item >= 0 && item < len(b.itemBias) {
+		estimate += b.itemBias[item]
+	}
+	return estimate
+}
+
+// residuals returns a copy of the ratings with the baseline's estimate taken off every rating
+func (b *Baseline) residuals(ratings *Ratings) *Ratings {
+	residuals := NewRatings()
+	residuals.Grow(ratings.NumUsers(), ratings.NumItems())
+	for _, rating := range ratings.All() {
+		residuals.Add(rating.User, rating.Item, rating.Value-b.estimate(rating.User, rating.Item))
+	}
+	return residuals
+}
+
+// Predict implements Predictor. The prediction is not OK when the user or the movie the kind depends on has
+// no ratings; the bias of such a user or movie is 0, so the estimate is still finite.
+func (b *Baseline) Predict(user, item int) Prediction {
+	ok := b.ratings.Len() > 0
+	if b.Kind == UserMean || b.Kind == UserItemBias {
+		ok = ok && len(b.ratings.UserRatings(user)) > 0
+	}
+	if b.Kind == ItemMean || b.Kind == UserItemBias {
+		ok = ok && len(b.ratings.ItemRatings(item)) > 0
+	}
+
+	return Prediction{User: user, Item: item, Value: b.estimate(user, item), OK: ok}
+}
+
+// PredictBatch implements Predictor
+func (b *Baseline) PredictBatch(pairs []Pair) []Prediction {
+	return predictBatch(b, pairs)
+}
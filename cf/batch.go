@@ -0,0 +1,73 @@
+package cf
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// BatchOptions controls how PredictParallel spreads predictions over goroutines
+type BatchOptions struct {
+	// Workers is the number of goroutines making predictions; 0 means one per CPU
+	Workers int
+
+	// Progress, if set, is called with the number of predictions made so far and the total after every
+	// chunk of predictions. Calls are never concurrent, and done only increases.
+	Progress func(done, total int)
+}
+
+// batchChunk is the number of consecutive pairs a worker predicts before taking more work
+const batchChunk = 64
+
+// PredictParallel returns a prediction for every pair, in the same order as pairs, using a pool of workers
+// that each call p.Predict. The predictor must be fitted and safe to use from several goroutines, as every
+// predictor in this package is. When ctx is cancelled PredictParallel stops handing out work and returns
+// ctx's error along with the predictions made so far; the rest are left as zero Predictions.
+func PredictParallel(ctx context.Context, p Predictor, pairs []Pair, opts BatchOptions) ([]Prediction, error) {
+	predictions := make([]Prediction, len(pairs))
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	chunks := make(chan int)
+	var wg sync.WaitGroup
+	var progress sync.Mutex
+	done := 0
+
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range chunks {
+				end := min(start+batchChunk, len(pairs))
+				for idx := start; idx < end; idx++ {
+					predictions[idx] = p.Predict(pairs[idx].User, pairs[idx].Item)
+				}
+
+				if opts.Progress != nil {
+					progress.Lock()
+					done += end - start
+					opts.Progress(done, len(pairs))
+					progress.Unlock()
+				}
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for start := 0; start < len(pairs); start += batchChunk {
+		select {
+		case chunks <- start:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(chunks)
+	wg.Wait()
+
+	return predictions, err
+}
@@ -0,0 +1,222 @@
+package cf
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SimilarityMatrix holds precomputed similarities between pairs of users or movies, so that each pair is
+// scored once when a predictor is fitted rather than once for every prediction it takes part in. Lookups
+// are symmetric: Similarity(a, b) equals Similarity(b, a).
+type SimilarityMatrix interface {
+	// Similarity returns the similarity between vectors a and b, or the zero Similarity if it was not kept
+	Similarity(a, b int) Similarity
+
+	// Len returns the number of vectors
+	Len() int
+}
+
+// DenseSimilarities keeps the similarity between every pair of vectors. It uses memory proportional to
+// the square of the number of vectors, so it suits data sets the size of train.txt.
+type DenseSimilarities struct {
+	n      int
+	values []Similarity // Lower triangle, row by row: the pair (a, b) with a > b is at a*(a-1)/2 + b
+}
+
+// NewDenseSimilarities scores every pair of vectors with fn
+func NewDenseSimilarities(v *Vectors, fn SimilarityFunc) *DenseSimilarities {
+	n := v.Len()
+	d := &DenseSimilarities{n: n, values: make([]Similarity, n*(n-1)/2)}
+
+	forEachRow(n, func(a int) {
+		row := d.values[a*(a-1)/2:]
+		for b := 0; b < a; b++ {
+			row[b] = fn(v, a, b)
+		}
+	})
+
+	return d
+}
+
+// Similarity implements SimilarityMatrix
+func (d *DenseSimilarities) Similarity(a, b int) Similarity {
+	if a == b {
+		return Similarity{}
+	}
+	if a < b {
+		a, b = b, a
+	}
+	return d.values[a*(a-1)/2+b]
+}
+
+// Len implements SimilarityMatrix
+func (d *DenseSimilarities) Len() int {
+	return d.n
+}
+
+// SparseSimilarities keeps only the N most similar vectors to every vector, ranked by the absolute value of
+// their similarity, so that its memory grows linearly with the number of vectors. Only vectors that share
+// at least one rating are scored. A pair is kept if either vector is among the other's N most similar.
+type SparseSimilarities struct {
+	rows [][]neighbourSimilarity // Sorted by index
+}
+
+// neighbourSimilarity is an entry in a row of SparseSimilarities
+type neighbourSimilarity struct {
+	index      int
+	similarity Similarity
+}
+
+// NewSparseSimilarities scores every pair of vectors that share a rating with fn and keeps the n most
+// similar vectors to each one
+func NewSparseSimilarities(v *Vectors, fn SimilarityFunc, n int) *SparseSimilarities {
+	s := &SparseSimilarities{rows: make([][]neighbourSimilarity, v.Len())}
+
+	forEachRow(v.Len(), func(a int) {
+		// Find every vector sharing a rating with a by going through the other dimension
+		seen := make([]bool, v.Len())
+		seen[a] = true
+		var row []neighbourSimilarity
+		for _, entry := range v.Entries(a) {
+			var others []Entry
+			if v.itemBased {
+				others = v.ratings.UserRatings(entry.Index)
+			} else {
+				others = v.ratings.ItemRatings(entry.Index)
+			}
+
+			for _, other := range others {
+				if !seen[other.Index] {
+					seen[other.Index] = true
+					row = append(row, neighbourSimilarity{index: other.Index, similarity: fn(v, a, other.Index)})
+				}
+			}
+		}
+
+		sort.SliceStable(row, func(i, j int) bool {
+			return math.Abs(row[i].similarity.Score) > math.Abs(row[j].similarity.Score)
+		})
+		if len(row) > n {
+			row = row[:n]
+		}
+		sort.Slice(row, func(i, j int) bool { return row[i].index < row[j].index })
+
+		s.rows[a] = row
+	})
+
+	return s
+}
+
+// Similarity implements SimilarityMatrix
+func (s *SparseSimilarities) Similarity(a, b int) Similarity {
+	if similarity, ok := s.lookup(a, b); ok {
+		return similarity
+	}
+	similarity, _ := s.lookup(b, a)
+	return similarity
+}
+
+// lookup returns b's similarity from a's row, if it was kept
+func (s *SparseSimilarities) lookup(a, b int) (Similarity, bool) {
+	row := s.rows[a]
+	idx := sort.Search(len(row), func(i int) bool { return row[i].index >= b })
+	if idx < len(row) && row[idx].index == b {
+		return row[idx].similarity, true
+	}
+	return Similarity{}, false
+}
+
+// Len implements SimilarityMatrix
+func (s *SparseSimilarities) Len() int {
+	return len(s.rows)
+}
+
+// forEachRow calls fn for every row from 0 to n-1, spread over one goroutine per CPU
+func forEachRow(n int, fn func(row int)) {
+	rows := make(chan int)
+	var wg sync.WaitGroup
+
+	for worker := 0; worker < runtime.GOMAXPROCS(0); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range rows {
+				fn(row)
+			}
+		}()
+	}
+
+	for row := 0; row < n; row++ {
+		rows <- row
+	}
+	close(rows)
+	wg.Wait()
+}
+
+// Cache decides whether and how a predictor precomputes its similarities when it is fitted
+type Cache int
+
+const (
+	// CacheAuto keeps every similarity for up to MaxDenseVectors users or movies and the most similar ones
+	// to each otherwise
+	CacheAuto Cache = iota
+
+	// CacheNone computes similarities as predictions need them
+	CacheNone
+
+	// CacheDense keeps every similarity; see DenseSimilarities
+	CacheDense
+
+	// CacheSparse keeps the most similar users or movies to each one; see SparseSimilarities
+	CacheSparse
+)
+
+// MaxDenseVectors is the largest number of users or movies CacheAuto keeps every similarity for
+const MaxDenseVectors = 5000
+
+// DefaultCacheNeighbours is the number of similarities per user or movie CacheSparse keeps by default
+const DefaultCacheNeighbours = 200
+
+var cacheNames = []string{"auto", "none", "dense", "sparse"}
+
+// String returns the cache mode's name
+func (c Cache) String() string {
+	if c < 0 || int(c) >= len(cacheNames) {
+		return fmt.Sprintf("Cache(%d)", int(c))
+	}
+	return cacheNames[c]
+}
+
+// ParseCache returns the cache mode with the given name: "auto", "none", "dense" or "sparse"
+func ParseCache(name string) (Cache, error) {
+	for idx, cacheName := range cacheNames {
+		if name == cacheName {
+			return Cache(idx), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown cache %q (available: %s)", name, strings.Join(cacheNames, ", "))
+}
+
+// build returns the similarity matrix for the cache mode, or nil for CacheNone. neighbours is the number of
+// similarities per vector a sparse matrix keeps.
+func (c Cache) build(v *Vectors, fn SimilarityFunc, neighbours int) SimilarityMatrix {
+	if c == CacheAuto {
+		c = CacheDense
+		if v.Len() > MaxDenseVectors {
+			c = CacheSparse
+		}
+	}
+
+	switch c {
+	case CacheDense:
+		return NewDenseSimilarities(v, fn)
+	case CacheSparse:
+		return NewSparseSimilarities(v, fn, neighbours)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,48 @@
+package cf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Confidence selects how implicit ALS turns the strength r of an interaction, such as a number of views,
+// into its confidence that the user likes the movie
+type Confidence int
+
+const (
+	// LinearConfidence is 1 + alpha * r
+	LinearConfidence Confidence = iota
+
+	// LogConfidence is 1 + alpha * log(1 + r / epsilon), which keeps users who watch a movie many times
+	// from dominating
+	LogConfidence
+)
+
+var confidenceNames = []string{"linear", "log"}
+
+// String returns the confidence function's name
+func (c Confidence) String() string {
+	if c < 0 || int(c) >= len(confidenceNames) {
+		return fmt.Sprintf("Confidence(%d)", int(c))
+	}
+	return confidenceNames[c]
+}
+
+// ParseConfidence returns the confidence function with the given name: "linear" or "log"
+func ParseConfidence(name string) (Confidence, error) {
+	for idx, confidenceName := range confidenceNames {
+		if name == confidenceName {
+			return Confidence(idx), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown confidence %q (available: %s)", name, strings.Join(confidenceNames, ", "))
+}
+
+// of returns the confidence in an interaction of strength r
+func (c Confidence) of(r, alpha, epsilon float64) float64 {
+	if c == LogConfidence {
+		return 1 + alpha*math.Log(1+r/epsilon)
+	}
+	return 1 + alpha*r
+}
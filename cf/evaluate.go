@@ -0,0 +1,41 @@
+package cf
+
+// TestUsers returns every existing rating made by the users from firstTestUser onwards. This is the
+// report's user-based test setup, where the first 175 users are used for training and the last 25 for testing.
+func TestUsers(ratings *Ratings, firstTestUser int) []Rating {
+	var test []Rating
+
+	for user := firstTestUser; user < ratings.NumUsers(); user++ {
+		for _, movie := range ratings.UserRatings(user) {
+			test = append(test, Rating{User: user, Item: movie.Index, Value: movie.Value})
+		}
+	}
+
+	return test
+}
+
+// TestItems returns every existing rating of the movies from firstTestItem onwards. This is the report's
+// item-based test setup, where the first 900 movies are used for training and the last 100 for testing.
+func TestItems(ratings *Ratings, firstTestItem int) []Rating {
+	var test []Rating
+
+	for user := 0; user < ratings.NumUsers(); user++ {
+		for _, movie := range ratings.UserRatings(user) {
+			if movie.Index >= firstTestItem {
+				test = append(test, Rating{User: user, Item: movie.Index, Value: movie.Value})
+			}
+		}
+	}
+
+	return test
+}
+
+// Pairs returns the (user, movie) pair of every rating
+func Pairs(ratings []Rating) []Pair {
+	pairs := make([]Pair, len(ratings))
+	for idx, rating := range ratings {
+		pairs[idx] = Pair{User: rating.User, Item: rating.Item}
+	}
+
+	return pairs
+}
@@ -0,0 +1,101 @@
+package cf
+
+import (
+	"math/rand"
+)
+
+// factorModel is a biased matrix factorization: a rating is predicted as the global mean plus the user's
+// and the movie's biases plus the dot product of the user's and the movie's latent factors. Algorithms
+// that do not learn biases leave them at 0.
+type factorModel struct {
+	mean        float64
+	userBias    []float64
+	itemBias    []float64
+	userFactors [][]float64
+	itemFactors [][]float64
+
+	ratings *Ratings // The ratings the model was fitted on, to tell which users and movies it knows
+}
+
+// newFactorModel returns a model for the users and movies in ratings, with its factors drawn from a normal
+// distribution with mean 0 and standard deviation initStd
+func newFactorModel(ratings *Ratings, factors int, initStd float64, random *rand.Rand) *factorModel {
+	m := &factorModel{
+		mean:     globalMean(ratings),
+		userBias: make([]float64, ratings.NumUsers()),
+		itemBias: make([]float64, ratings.NumItems()),
+		ratings:  ratings,
+	}
+
+	m.userFactors = randomFactors(ratings.NumUsers(), factors, initStd, random)
+	m.itemFactors = randomFactors(ratings.NumItems(), factors, initStd, random)
+
+	return m
+}
+
+// randomFactors returns n vectors of normally distributed factors
+func randomFactors(n, factors int, std float64, random *rand.Rand) [][]float64 {
+	vectors := make([][]float64, n)
+	for idx := range vectors {
+		vectors[idx] = make([]float64, factors)
+		for factor := range vectors[idx] {
+			vectors[idx][factor] = random.NormFloat64() * std
+		}
+	}
+	return vectors
+}
+
+// predict returns the model's prediction using the given user factors, which are the user's own factors
+// for most algorithms. Users and movies without any ratings contribute neither a bias nor factors, and
+// predictions for them are not OK.
+func (m *factorModel) predict(user, item int, userFactors []float64) Prediction {
+	prediction := Prediction{User: user, Item: item, Value: m.mean, OK: true}
+
+	if len(m.ratings.UserRatings(user)) > 0 {
+		prediction.Value += m.userBias[user]
+	} else {
+		prediction.OK = false
+	}
+	if len(m.ratings.ItemRatings(item)) > 0 {
+		prediction.Value += m.itemBias[item]
+	} else {
+		prediction.OK = false
+	}
+	if prediction.OK {
+		prediction.Value += dot(userFactors, m.itemFactors[item])
+	}
+
+	return prediction
+}
+
+// userFactorsOf returns the user's own factors, or nil for users the model does not know
+func (m *factorModel) userFactorsOf(user int) []float64 {
+	if user < 0 || user >= len(m.userFactors) {
+		return nil
+	}
+	return m.userFactors[user]
+}
+
+// globalMean returns the average of every rating, or 0 if there are none
+func globalMean(ratings *Ratings) float64 {
+	if ratings.Len() == 0 {
+		return 0
+	}
+
+	var sumOfRatings float64 = 0
+	for user := 0; user < ratings.NumUsers(); user++ {
+		for _, entry := range ratings.UserRatings(user) {
+			sumOfRatings += entry.Value
+		}
+	}
+	return sumOfRatings / float64(ratings.Len())
+}
+
+// dot returns the dot product of two vectors of the same length
+func dot(a, b []float64) float64 {
+	var sum float64 = 0
+	for idx := range a {
+		sum += a[idx] * b[idx]
+	}
+	return sum
+}
@@ -0,0 +1,150 @@
+package cf
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// ImplicitALS is Hu, Koren and Volinsky's alternating least squares for implicit feedback, such as views
+// or clicks, instead of ratings. Every value in the ratings it is fitted on is the strength of an
+// interaction, such as a count or simply 1; values of 0 or less are ignored. Every (user, movie) pair is
+// given a preference of 1 if they interacted and 0 otherwise, and q_i · p_u is fitted to the preferences
+// weighted by the confidence in each: 1 for pairs without an interaction and Confidence otherwise.
+//
+// Predictions are preference scores, roughly between 0 and 1, rather than ratings; they are meant for
+// ranking with Recommend and the top-N metrics, not for RMSE. The embedded ALS holds the factors,
+// iterations, seed and regularization, which here is a plain λ not weighted by the number of interactions.
+type ImplicitALS struct {
+	ALS
+
+	Confidence Confidence
+	Alpha      float64 // Scales the strength of interactions into confidence
+	Epsilon    float64 // Scales the strength of interactions for LogConfidence
+}
+
+// NewImplicitALS returns an implicit ALS with 20 factors solved for 15 iterations with λ = 0.1 and linear
+// confidence with alpha = 40, as in the paper
+func NewImplicitALS() *ImplicitALS {
+	return &ImplicitALS{ALS: ALS{Factors: 20, Iterations: 15, Regularization: 0.1, InitStd: 0.1}, Alpha: 40, Epsilon: 1}
+}
+
+// newImplicitALS returns a constructor for implicit ALS predictors that start out as a copy of def
+func newImplicitALS(def ImplicitALS) func(params Params) (Predictor, error) {
+	return func(params Params) (Predictor, error) {
+		if err := params.check(append(alsParamNames, "confidence", "alpha", "epsilon")...); err != nil {
+			return nil, err
+		}
+
+		p := def
+		if err := p.alsParams(params); err != nil {
+			return nil, err
+		}
+		var err error
+		if confidence, ok := params["confidence"]; ok {
+			if p.Confidence, err = ParseConfidence(confidence); err != nil {
+				return nil, err
+			}
+		}
+		if p.Alpha, err = params.Float("alpha", p.Alpha); err != nil {
+			return nil, err
+		}
+		if p.Epsilon, err = params.Float("epsilon", p.Epsilon); err != nil {
+			return nil, err
+		}
+
+		return &p, nil
+	}
+}
+
+// Fit implements Predictor
+func (p *ImplicitALS) Fit(ratings *Ratings) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	if p.Alpha < 0 || p.Epsilon <= 0 {
+		return fmt.Errorf("alpha must not be negative and epsilon must be positive")
+	}
+
+	m := newFactorModel(ratings, p.Factors, p.InitStd, rand.New(rand.NewSource(p.Seed)))
+	m.mean = 0
+
+	var failed error
+	var failedOnce sync.Once
+	halfStep := func(n int, entriesOf func(int) []Entry, others, result [][]float64) {
+		gram := gramMatrix(others, p.Factors)
+		forEachRow(n, func(row int) {
+			if err := p.solve(entriesOf(row), others, gram, result[row]); err != nil {
+				failedOnce.Do(func() { failed = err })
+			}
+		})
+	}
+
+	for iteration := 0; iteration < p.Iterations; iteration++ {
+		halfStep(ratings.NumUsers(), ratings.UserRatings, m.itemFactors, m.userFactors)
+		halfStep(ratings.NumItems(), ratings.ItemRatings, m.userFactors, m.itemFactors)
+		if failed != nil {
+			return failed
+		}
+	}
+
+	p.model = m
+	return nil
+}
+
+// solve sets result to the factors that best fit the preferences of one user or movie given the fixed
+// factors of the other side: the solution of (YᵀY + summation( (c - 1) · y · yᵀ ) + λ · I) · result =
+// summation( c · y ), where both summations are over the interactions in entries. gram is YᵀY.
+func (p *ImplicitALS) solve(entries []Entry, others [][]float64, gram []float64, result []float64) error {
+	n := len(result)
+	for factor := range result {
+		result[factor] = 0
+	}
+
+	a := append([]float64(nil), gram...) // Represents: YᵀY + summation( (c - 1) · y · yᵀ ) + λ · I
+	for _, entry := range entries {
+		if entry.Value <= 0 {
+			continue
+		}
+		confidence := p.Confidence.of(entry.Value, p.Alpha, p.Epsilon)
+		other := others[entry.Index]
+		for row := 0; row < n; row++ {
+			for col := 0; col <= row; col++ {
+				a[row*n+col] += (confidence - 1) * other[row] * other[col]
+			}
+			result[row] += confidence * other[row]
+		}
+	}
+	for row := 0; row < n; row++ {
+		a[row*n+row] += p.Regularization
+		for col := 0; col < row; col++ {
+			a[col*n+row] = a[row*n+col]
+		}
+	}
+
+	return solveSymmetric(a, result, n)
+}
+
+// gramMatrix returns the n by n matrix summation( v · vᵀ ) over every vector, stored row by row
+func gramMatrix(vectors [][]float64, n int) []float64 {
+	gram := make([]float64, n*n)
+	for _, vector := range vectors {
+		for row := 0; row < n; row++ {
+			for col := 0; col < n; col++ {
+				gram[row*n+col] += vector[row] * vector[col]
+			}
+		}
+	}
+	return gram
+}
+
+// Predict implements Predictor. The prediction is a preference score; it is not OK for users and movies
+// without any interactions.
+func (p *ImplicitALS) Predict(user, item int) Prediction {
+	return p.model.predict(user, item, p.model.userFactorsOf(user))
+}
+
+// PredictBatch implements Predictor
+func (p *ImplicitALS) PredictBatch(pairs []Pair) []Prediction {
+	return predictBatch(p, pairs)
+}
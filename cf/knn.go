@@ -0,0 +1,332 @@
+package cf
+
+import (
+	"fmt"
+	"math"
+)
+
+// KNN is neighbourhood-based collaborative filtering. User-based KNN predicts a user's rating of a movie from
+// the ratings the most similar other users gave it; item-based KNN predicts it from the user's own ratings of
+// the movies most similar to it. Every algorithm from the project report is a KNN with a particular set of
+// options; see New.
+type KNN struct {
+	ItemBased bool // Compare movies instead of users
+
+	// Similarity is the name of the similarity function in the registry used to compare users or movies
+	Similarity string
+
+	// Neighbourhood selects the similar users or movies a prediction is based on
+	Neighbourhood Neighbourhood
+
+	// MeanCentered aggregates how far the neighbours' ratings are from their own average ratings, weighted by
+	// the absolute value of their similarity, and adds that to the active user's (or desired movie's) average
+	// rating. Otherwise a prediction is the similarity-weighted average of the neighbours' raw ratings. Only
+	// mean-centered predictions rank neighbours by the absolute value of their similarity, so that strongly
+	// negatively correlated neighbours are used too.
+	MeanCentered bool
+
+	// CaseModification is the case amplification exponent p; every similarity w is replaced by
+	// w * |w|^(p-1), which emphasizes similarities close to 1 and -1. Values of 0 and 1 disable it.
+	CaseModification float64
+
+	// Significance is Herlocker et al.'s significance weighting threshold: similarities computed from fewer
+	// than Significance co-ratings are scaled down in proportion. 0 disables it.
+	Significance int
+
+	// Shrinkage is Bell and Koren's shrinkage parameter lambda: every similarity is scaled by n / (n + lambda),
+	// where n is the number of co-ratings. 0 disables it. It can be combined with Significance.
+	Shrinkage float64
+
+	// Weighting re-weights the ratings used to compare users. Predictions always use raw ratings. It is only
+	// supported for user-based KNN.
+	Weighting Weighting
+
+	// Cache decides how similarities are precomputed when the predictor is fitted. The zero value keeps
+	// every similarity for data sets the size of train.txt.
+	Cache Cache
+
+	// CacheNeighbours is the number of similarities per user or movie a sparse cache keeps; 0 means
+	// DefaultCacheNeighbours
+	CacheNeighbours int
+
+	// Scale is the rating scale, used by similarities such as constrained Pearson. The zero value means DefaultScale.
+	Scale Scale
+
+	// Baseline, unless its Kind is NoBaseline, replaces the user's average rating as the fallback for
+	// predictions without neighbours and, when MeanCentered, replaces the averages ratings are centered on:
+	// a prediction is the baseline's estimate for the pair plus the weighted average of how far the
+	// neighbours' ratings are from the baseline's estimates for them.
+	Baseline Baseline
+
+	// Candidates, if not 0, only lets the first Candidates users (or movies) be neighbours, as in the report's
+	// test setups where the first 175 users or 900 movies are the training data
+	Candidates int
+
+	// Residuals compares users or movies on how far their ratings are from the Baseline's estimates, rather
+	// than on the ratings themselves. It needs a Baseline.
+	Residuals bool
+
+	ratings    *Ratings
+	vectors    *Vectors
+	similarity SimilarityFunc
+	matrix     SimilarityMatrix // nil when similarities are not cached
+	mean       float64          // Average of every rating, the fallback for users without ratings
+	userMeans  []float64
+	itemMeans  []float64
+}
+
+// NewUserKNN returns a mean-centered user-based predictor using the named similarity and the report's
+// neighbourhood of the 20 most similar users
+func NewUserKNN(similarity string) *KNN {
+	return &KNN{Similarity: similarity, Neighbourhood: TopK(DefaultNeighbours), MeanCentered: true}
+}
+
+// NewItemKNN returns a mean-centered item-based predictor using the named similarity and the report's
+// neighbourhood of the 20 most similar movies
+func NewItemKNN(similarity string) *KNN {
+	return &KNN{ItemBased: true, Similarity: similarity, Neighbourhood: TopK(DefaultNeighbours), MeanCentered: true}
+}
+
+// NewKNNBaseline returns Koren's kNN with baselines: a mean-centered predictor whose ratings are centered on
+// a regularized user and movie bias baseline, comparing users (or movies, if itemBased) by the cosine of
+// their residuals shrunk with lambda = 100, with a neighbourhood of the 40 most similar
+func NewKNNBaseline(itemBased bool) *KNN {
+	return &KNN{ItemBased: itemBased, Similarity: "cosine", Neighbourhood: TopK(40), MeanCentered: true, Shrinkage: 100,
+		Baseline: *NewBaseline(UserItemBias), Residuals: true}
+}
+
+// newKNN returns a constructor for KNN predictors that start out as a copy of def
+func newKNN(def KNN) func(params Params) (Predictor, error) {
+	return func(params Params) (Predictor, error) {
+		allowed := append([]string{"similarity", "centered", "p", "significance", "shrinkage", "cache", "cache-neighbours", "baseline", "residuals"}, neighbourhoodParamNames...)
+		if !def.ItemBased {
+			allowed = append(allowed, "weighting")
+		}
+		if err := params.check(allowed...); err != nil {
+			return nil, err
+		}
+
+		p := def
+		var err error
+
+		p.Similarity = params.String("similarity", p.Similarity)
+		if p.MeanCentered, err = params.Bool("centered", p.MeanCentered); err != nil {
+			return nil, err
+		}
+		if p.CaseModification, err = params.Float("p", p.CaseModification); err != nil {
+			return nil, err
+		}
+		if p.Significance, err = params.Int("significance", p.Significance); err != nil {
+			return nil, err
+		}
+		if p.Shrinkage, err = params.Float("shrinkage", p.Shrinkage); err != nil {
+			return nil, err
+		}
+		if cache, ok := params["cache"]; ok {
+			if p.Cache, err = ParseCache(cache); err != nil {
+				return nil, err
+			}
+		}
+		if p.CacheNeighbours, err = params.Int("cache-neighbours", p.CacheNeighbours); err != nil {
+			return nil, err
+		}
+		if weighting, ok := params["weighting"]; ok {
+			if p.Weighting, err = ParseWeighting(weighting); err != nil {
+				return nil, err
+			}
+		}
+		if baseline, ok := params["baseline"]; ok {
+			kind, err := ParseBaselineKind(baseline)
+			if err != nil {
+				return nil, err
+			}
+			p.Baseline = *NewBaseline(kind)
+		}
+		if p.Residuals, err = params.Bool("residuals", p.Residuals); err != nil {
+			return nil, err
+		}
+		if p.Neighbourhood, err = neighbourhoodParams(params, p.Neighbourhood); err != nil {
+			return nil, err
+		}
+
+		return &p, nil
+	}
+}
+
+// Fit implements Predictor
+func (p *KNN) Fit(ratings *Ratings) error {
+	if err := p.Neighbourhood.validate(); err != nil {
+		return err
+	}
+	if p.ItemBased && p.Weighting != NoWeighting {
+		return fmt.Errorf("%v weighting is only supported for user-based KNN", p.Weighting)
+	}
+	if p.Significance < 0 || p.Shrinkage < 0 {
+		return fmt.Errorf("significance and shrinkage must not be negative")
+	}
+	if p.CacheNeighbours < 0 || p.Candidates < 0 {
+		return fmt.Errorf("cache neighbours and candidates must not be negative")
+	}
+	if p.Residuals && p.Baseline.Kind == NoBaseline {
+		return fmt.Errorf("comparing residuals needs a baseline")
+	}
+
+	similarity, err := LookupSimilarity(p.Similarity)
+	if err != nil {
+		return err
+	}
+
+	if p.Baseline.Kind != NoBaseline {
+		if err := p.Baseline.Fit(ratings); err != nil {
+			return err
+		}
+	}
+	compared := ratings
+	if p.Residuals {
+		compared = p.Baseline.residuals(ratings)
+	}
+
+	weighted, err := p.Weighting.weighted(compared)
+	if err != nil {
+		return err
+	}
+
+	scale := p.Scale
+	if scale == (Scale{}) {
+		scale = DefaultScale
+	}
+
+	p.ratings = ratings
+	p.similarity = Modify(similarity, SignificanceWeighting(p.Significance), Shrinkage(p.Shrinkage))
+	p.vectors = NewVectors(weighted, p.ItemBased, scale)
+
+	cacheNeighbours := p.CacheNeighbours
+	if cacheNeighbours == 0 {
+		cacheNeighbours = DefaultCacheNeighbours
+	}
+	p.matrix = p.Cache.build(p.vectors, p.similarity, cacheNeighbours)
+
+	p.mean = globalMean(ratings)
+	p.userMeans = make([]float64, ratings.NumUsers())
+	for user := range p.userMeans {
+		p.userMeans[user] = ratings.UserMean(user)
+	}
+	p.itemMeans = make([]float64, ratings.NumItems())
+	for item := range p.itemMeans {
+		p.itemMeans[item] = ratings.ItemMean(item)
+	}
+
+	return nil
+}
+
+// Predict implements Predictor. Predictions without neighbours, including those for users and movies outside
+// the ratings the predictor was fitted on, fall back on the value fallback returns and are not OK.
+func (p *KNN) Predict(user, item int) Prediction {
+	if user < 0 || user >= len(p.userMeans) || item < 0 || item >= len(p.itemMeans) {
+		return Prediction{User: user, Item: item, Value: p.fallback(user, item), OK: false}
+	}
+
+	var candidates []Neighbour
+	var means []float64  // Average ratings of the neighbours
+	var base float64 = 0 // Average rating a mean-centered prediction starts from
+
+	if p.ItemBased {
+		// Score every other movie the user has rated by how similar it is to the desired movie
+		means, base = p.itemMeans, p.itemMeans[item]
+		for _, otherMovie := range p.ratings.UserRatings(user) {
+			if otherMovie.Index != item && p.candidate(otherMovie.Index) {
+				candidates = append(candidates, p.neighbour(item, otherMovie.Index))
+			}
+		}
+	} else {
+		// Score every other user who has rated the movie by how similar they are to the active user
+		means, base = p.userMeans, p.userMeans[user]
+		for _, otherUser := range p.ratings.ItemRatings(item) {
+			if otherUser.Index != user && p.candidate(otherUser.Index) {
+				candidates = append(candidates, p.neighbour(user, otherUser.Index))
+			}
+		}
+	}
+
+	neighbours := p.Neighbourhood.Select(candidates, p.MeanCentered)
+
+	baselined := p.MeanCentered && p.Baseline.Kind != NoBaseline
+	if baselined {
+		base = p.Baseline.estimate(user, item)
+	}
+
+	var summation1 float64 = 0 // Represents: summation( Similarity_Score * Neighbour_Rating ), less the neighbour's average or baseline estimate when mean-centered
+	var summation2 float64 = 0 // Represents: summation( Similarity_Score ), or of its absolute value when mean-centered
+
+	for _, neighbour := range neighbours {
+		neighbourUser, neighbourItem := neighbour.Index, item
+		if p.ItemBased {
+			neighbourUser, neighbourItem = user, neighbour.Index
+		}
+		neighbourRating, _ := p.ratings.Get(neighbourUser, neighbourItem)
+
+		offset := means[neighbour.Index] // What the neighbour's rating is centered on when mean-centered
+		if baselined {
+			offset = p.Baseline.estimate(neighbourUser, neighbourItem)
+		}
+
+		if p.MeanCentered {
+			summation1 += neighbour.Similarity * (neighbourRating - offset)
+			summation2 += math.Abs(neighbour.Similarity)
+		} else {
+			summation1 += neighbour.Similarity * neighbourRating
+			summation2 += neighbour.Similarity
+		}
+	}
+
+	if !p.MeanCentered {
+		base = 0
+	}
+	prediction := base + (summation1 / summation2)
+
+	if math.IsNaN(prediction) || math.IsInf(prediction, 0) {
+		return Prediction{User: user, Item: item, Value: p.fallback(user, item), OK: false}
+	}
+
+	return Prediction{User: user, Item: item, Value: prediction, OK: true}
+}
+
+// PredictBatch implements Predictor
+func (p *KNN) PredictBatch(pairs []Pair) []Prediction {
+	return predictBatch(p, pairs)
+}
+
+// fallback returns the value predicted without neighbours: the Baseline's estimate if there is one, otherwise
+// the user's average rating, or the average of every rating for users without any
+func (p *KNN) fallback(user, item int) float64 {
+	if p.Baseline.Kind != NoBaseline {
+		return p.Baseline.estimate(user, item)
+	}
+	if len(p.ratings.UserRatings(user)) == 0 {
+		return p.mean
+	}
+	return p.userMeans[user]
+}
+
+// candidate reports whether the user or movie may be a neighbour
+func (p *KNN) candidate(index int) bool {
+	return p.Candidates == 0 || index < p.Candidates
+}
+
+// neighbour returns user or movie b as a candidate neighbour of a, scored by their (possibly significance
+// weighted, shrunk and case modified) similarity
+func (p *KNN) neighbour(a, b int) Neighbour {
+	var similarity Similarity
+	if p.matrix != nil {
+		similarity = p.matrix.Similarity(a, b)
+	} else {
+		similarity = p.similarity(p.vectors, a, b)
+	}
+	score := similarity.Score
+
+	if p.CaseModification != 0 && p.CaseModification != 1 {
+		score *= math.Pow(math.Abs(score), p.CaseModification-1)
+	}
+
+	return Neighbour{Index: b, Similarity: finite(score), CoRatings: similarity.CoRatings}
+}
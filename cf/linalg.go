@@ -0,0 +1,47 @@
+package cf
+
+import (
+	"fmt"
+	"math"
+)
+
+// solveSymmetric solves a·x = b for x, where a is an n by n symmetric positive definite matrix stored row
+// by row, using its Cholesky decomposition. a is overwritten by the decomposition and b by x.
+func solveSymmetric(a, b []float64, n int) error {
+	// Decompose a into l·lᵀ, keeping l in the lower triangle of a
+	for row := 0; row < n; row++ {
+		for col := 0; col <= row; col++ {
+			sum := a[row*n+col]
+			for k := 0; k < col; k++ {
+				sum -= a[row*n+k] * a[col*n+k]
+			}
+
+			if row == col {
+				if sum <= 0 {
+					return fmt.Errorf("matrix is not positive definite")
+				}
+				a[row*n+row] = math.Sqrt(sum)
+			} else {
+				a[row*n+col] = sum / a[col*n+col]
+			}
+		}
+	}
+
+	// Solve l·y = b, then lᵀ·x = y
+	for row := 0; row < n; row++ {
+		sum := b[row]
+		for k := 0; k < row; k++ {
+			sum -= a[row*n+k] * b[k]
+		}
+		b[row] = sum / a[row*n+row]
+	}
+	for row := n - 1; row >= 0; row-- {
+		sum := b[row]
+		for k := row + 1; k < n; k++ {
+			sum -= a[k*n+row] * b[k]
+		}
+		b[row] = sum / a[row*n+row]
+	}
+
+	return nil
+}
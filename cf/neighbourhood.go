@@ -0,0 +1,184 @@
+package cf
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Neighbour is a user or movie that is a candidate to take part in a prediction, along with its
+// similarity to the active user or desired movie and the number of co-ratings the similarity is based on
+type Neighbour struct {
+	Index      int
+	Similarity float64
+	CoRatings  int
+}
+
+// Neighbourhood decides which candidate neighbours a prediction is based on. Candidates are ranked by
+// their score, which is the absolute value of their similarity for algorithms that make use of negative
+// correlations (such as Pearson) and the similarity itself otherwise. Candidates with a score of 0 never
+// take part in a prediction.
+//
+// The zero value keeps every candidate; TopK, Threshold, TopKAboveThreshold and AllPositive return the
+// common selection strategies.
+type Neighbourhood struct {
+	K            int     // Keep at most the K highest scoring candidates; 0 keeps all of them
+	MinScore     float64 // Only keep candidates scoring at least MinScore
+	PositiveOnly bool    // Only keep candidates with a positive similarity, even when negative ones could be used
+}
+
+// TopK returns a neighbourhood of the k most similar candidates, as used in the project report with k = 20
+func TopK(k int) Neighbourhood {
+	return Neighbourhood{K: k}
+}
+
+// Threshold returns a neighbourhood of every candidate scoring at least min
+func Threshold(min float64) Neighbourhood {
+	return Neighbourhood{MinScore: min}
+}
+
+// TopKAboveThreshold returns a neighbourhood of the k most similar candidates among those scoring at least min
+func TopKAboveThreshold(k int, min float64) Neighbourhood {
+	return Neighbourhood{K: k, MinScore: min}
+}
+
+// AllPositive returns a neighbourhood of every candidate with a positive similarity
+func AllPositive() Neighbourhood {
+	return Neighbourhood{PositiveOnly: true}
+}
+
+// Select returns the candidates a prediction should be based on, most similar first. Candidates with equal
+// scores keep their relative order, so earlier candidates win ties. byMagnitude ranks candidates by the
+// absolute value of their similarity. Select may reorder candidates.
+func (n Neighbourhood) Select(candidates []Neighbour, byMagnitude bool) []Neighbour {
+	score := func(similarity float64) float64 {
+		if byMagnitude {
+			return math.Abs(similarity)
+		}
+		return similarity
+	}
+
+	selected := candidates[:0]
+	for _, candidate := range candidates {
+		candidateScore := score(candidate.Similarity)
+		if candidateScore > 0 && candidateScore >= n.MinScore && (!n.PositiveOnly || candidate.Similarity > 0) {
+			selected = append(selected, candidate)
+		}
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		return score(selected[i].Similarity) > score(selected[j].Similarity)
+	})
+
+	if n.K > 0 && len(selected) > n.K {
+		selected = selected[:n.K]
+	}
+
+	return selected
+}
+
+func (n Neighbourhood) validate() error {
+	if n.K < 0 {
+		return fmt.Errorf("neighbourhood size must not be negative, got %d", n.K)
+	}
+	return nil
+}
+
+// String formats the neighbourhood in the form ParseNeighbourhood reads
+func (n Neighbourhood) String() string {
+	switch {
+	case n.PositiveOnly && n.K == 0 && n.MinScore == 0:
+		return "positive"
+	case n.PositiveOnly:
+		return fmt.Sprintf("positive:%d:%v", n.K, n.MinScore)
+	case n.K > 0 && n.MinScore == 0:
+		return fmt.Sprintf("top-k:%d", n.K)
+	case n.K == 0:
+		return fmt.Sprintf("threshold:%v", n.MinScore)
+	default:
+		return fmt.Sprintf("top-k-threshold:%d:%v", n.K, n.MinScore)
+	}
+}
+
+// ParseNeighbourhood parses a neighbourhood selection strategy: "top-k:<k>", "threshold:<min>",
+// "top-k-threshold:<k>:<min>", "positive", or "positive:<k>:<min>" to limit the positive neighbours further
+func ParseNeighbourhood(text string) (Neighbourhood, error) {
+	fields := strings.Split(text, ":")
+	invalid := fmt.Errorf("invalid neighbourhood %q (expected top-k:<k>, threshold:<min>, top-k-threshold:<k>:<min> or positive)", text)
+
+	parseK := func(field string) (int, error) {
+		k, err := strconv.Atoi(field)
+		if err != nil || k < 0 {
+			return 0, invalid
+		}
+		return k, nil
+	}
+	parseMin := func(field string) (float64, error) {
+		min, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return 0, invalid
+		}
+		return min, nil
+	}
+
+	switch {
+	case fields[0] == "top-k" && len(fields) == 2:
+		k, err := parseK(fields[1])
+		return TopK(k), err
+	case fields[0] == "threshold" && len(fields) == 2:
+		min, err := parseMin(fields[1])
+		return Threshold(min), err
+	case fields[0] == "top-k-threshold" && len(fields) == 3:
+		k, err := parseK(fields[1])
+		if err != nil {
+			return Neighbourhood{}, err
+		}
+		min, err := parseMin(fields[2])
+		return TopKAboveThreshold(k, min), err
+	case fields[0] == "positive" && len(fields) == 1:
+		return AllPositive(), nil
+	case fields[0] == "positive" && len(fields) == 3:
+		k, err := parseK(fields[1])
+		if err != nil {
+			return Neighbourhood{}, err
+		}
+		min, err := parseMin(fields[2])
+		return Neighbourhood{K: k, MinScore: min, PositiveOnly: true}, err
+	default:
+		return Neighbourhood{}, invalid
+	}
+}
+
+// Params returns the neighbourhood as the "neighbours", "threshold" and "positive" algorithm parameters
+func (n Neighbourhood) Params() Params {
+	params := Params{}
+	params.Set("neighbours", float64(n.K))
+	params.Set("threshold", n.MinScore)
+	if n.PositiveOnly {
+		params["positive"] = "true"
+	}
+	return params
+}
+
+// neighbourhoodParams returns def with any of the "neighbours", "threshold" and "positive" parameters applied
+func neighbourhoodParams(params Params, def Neighbourhood) (Neighbourhood, error) {
+	var err error
+	n := def
+
+	if n.K, err = params.Int("neighbours", n.K); err != nil {
+		return Neighbourhood{}, err
+	}
+	if n.MinScore, err = params.Float("threshold", n.MinScore); err != nil {
+		return Neighbourhood{}, err
+	}
+	if n.PositiveOnly, err = params.Bool("positive", n.PositiveOnly); err != nil {
+		return Neighbourhood{}, err
+	}
+
+	return n, n.validate()
+}
+
+// neighbourhoodParamNames are the parameter names neighbourhoodParams reads
+var neighbourhoodParamNames = []string{"neighbours", "threshold", "positive"}
@@ -0,0 +1,158 @@
+package cf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Params holds named algorithm parameters, such as "neighbours" or "similarity", that override an
+// algorithm's defaults when it is created with New. Values are kept as text and converted by the
+// algorithm that reads them.
+type Params map[string]string
+
+// Float returns the named parameter as a number, or def if it is not set
+func (params Params) Float(name string, def float64) (float64, error) {
+	text, ok := params[name]
+	if !ok {
+		return def, nil
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parameter %q must be a number, got %q", name, text)
+	}
+	return value, nil
+}
+
+// Int returns the named parameter as a whole number, or def if it is not set
+func (params Params) Int(name string, def int) (int, error) {
+	text, ok := params[name]
+	if !ok {
+		return def, nil
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || value != float64(int(value)) {
+		return 0, fmt.Errorf("parameter %q must be a whole number, got %q", name, text)
+	}
+	return int(value), nil
+}
+
+// Bool returns the named parameter as a boolean, or def if it is not set. Besides "true" and "false",
+// "1" and "0" are accepted.
+func (params Params) Bool(name string, def bool) (bool, error) {
+	text, ok := params[name]
+	if !ok {
+		return def, nil
+	}
+
+	value, err := strconv.ParseBool(text)
+	if err != nil {
+		return false, fmt.Errorf("parameter %q must be true or false, got %q", name, text)
+	}
+	return value, nil
+}
+
+// String returns the named parameter, or def if it is not set
+func (params Params) String(name string, def string) string {
+	if text, ok := params[name]; ok {
+		return text
+	}
+	return def
+}
+
+// Set stores a numeric parameter
+func (params Params) Set(name string, value float64) {
+	params[name] = strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// check returns an error naming the first parameter that is not in allowed
+func (params Params) check(allowed ...string) error {
+	for _, name := range params.Names() {
+		found := false
+		for _, allowedName := range allowed {
+			if name == allowedName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown parameter %q (available: %s)", name, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the parameters that are set, sorted
+func (params Params) Names() []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Format formats the parameters as sorted name=value pairs, the same format ParseParams reads
+func (params Params) Format() string {
+	pairs := make([]string, 0, len(params))
+	for _, name := range params.Names() {
+		pairs = append(pairs, name+"="+params[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// ParseParams parses comma separated name=value pairs, such as "neighbours=30,similarity=spearman"
+func ParseParams(text string) (Params, error) {
+	params := Params{}
+	if strings.TrimSpace(text) == "" {
+		return params, nil
+	}
+
+	for _, pair := range strings.Split(text, ",") {
+		name, value, found := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("parameter %q is not of the form name=value", pair)
+		}
+		params[name] = strings.TrimSpace(value)
+	}
+
+	return params, nil
+}
+
+// UnmarshalJSON reads parameters from a JSON object. Values may be strings, numbers or booleans, so that
+// configuration files can write {"neighbours": 30, "similarity": "spearman"}.
+func (params *Params) UnmarshalJSON(data []byte) error {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	*params = Params{}
+	for name, raw := range values {
+		var text string
+		if err := json.Unmarshal(raw, &text); err == nil {
+			(*params)[name] = text
+			continue
+		}
+
+		raw = bytes.TrimSpace(raw)
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+		switch value.(type) {
+		case float64, bool:
+			(*params)[name] = string(raw)
+		default:
+			return fmt.Errorf("parameter %q must be a string, number or boolean, got %s", name, raw)
+		}
+	}
+
+	return nil
+}
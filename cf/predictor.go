@@ -0,0 +1,132 @@
+// Package cf implements the collaborative filtering algorithms for movie recommendation
+// from the project report behind a common Predictor interface, so that callers can pick
+// an algorithm by name at runtime instead of running one program per variant.
+package cf
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// DefaultNeighbours is the neighbourhood size used throughout the project report
+const DefaultNeighbours = 20
+
+// Pair identifies a (user, movie) pair to make a prediction for
+type Pair struct {
+	User int
+	Item int
+}
+
+// Prediction is the predicted rating for a single (user, movie) pair. Value is not rounded or
+// limited to the rating scale; see OutputPolicy. OK is false when the algorithm could not find
+// any neighbours to base the prediction on and fell back to the user's average rating instead.
+type Prediction struct {
+	User  int
+	Item  int
+	Value float64
+	OK    bool
+}
+
+// Predictor is a collaborative filtering algorithm. Fit must be called before any predictions
+// are made; the ratings passed to Fit must not be modified afterwards.
+type Predictor interface {
+	// Fit prepares the predictor to make predictions using the given ratings
+	Fit(ratings *Ratings) error
+
+	// Predict returns the rating the user is predicted to give the movie
+	Predict(user, item int) Prediction
+
+	// PredictBatch returns a prediction for every pair, in the same order as pairs
+	PredictBatch(pairs []Pair) []Prediction
+}
+
+// ImplicitPredictor is a predictor that can also learn from which movies users rated without knowing the
+// ratings, such as the movies a given-N test file asks to be predicted
+type ImplicitPredictor interface {
+	Predictor
+
+	// AddImplicit records that every user rated the paired movie. It must be called before Fit.
+	AddImplicit(pairs []Pair)
+}
+
+// algorithm is an entry in the registry of algorithms
+type algorithm struct {
+	new     func(params Params) (Predictor, error)
+	space   Space // The parameters a hyperparameter search tunes
+	ranking bool  // Predicts preference scores for ranking movies rather than ratings
+}
+
+// algorithms maps the name of every available algorithm to a constructor, which uses the parameters from
+// the project report unless they are overridden, to its hyperparameter space and to whether it only ranks
+var algorithms = map[string]algorithm{
+	"cosine":        {new: newKNN(KNN{Similarity: "cosine", Neighbourhood: TopK(DefaultNeighbours)}), space: Space{neighboursRange}},
+	"pearson":       {new: newKNN(*NewUserKNN("pearson")), space: Space{neighboursRange, caseRange, weightingRange, significanceRange, shrinkageRange}},
+	"pearson-case":  {new: newKNN(KNN{Similarity: "pearson", Neighbourhood: TopK(DefaultNeighbours), MeanCentered: true, CaseModification: 3}), space: Space{neighboursRange, caseRange}},
+	"pearson-iuf":   {new: newKNN(KNN{Similarity: "pearson", Neighbourhood: TopK(DefaultNeighbours), MeanCentered: true, Weighting: IUFWeighting}), space: Space{neighboursRange, significanceRange}},
+	"pearson-polar": {new: newKNN(KNN{Similarity: "pearson", Neighbourhood: TopK(DefaultNeighbours), MeanCentered: true, Weighting: PolarizationWeighting}), space: Space{neighboursRange, significanceRange}},
+	"item-cosine":   {new: newKNN(KNN{ItemBased: true, Similarity: "cosine", Neighbourhood: TopK(DefaultNeighbours)}), space: Space{neighboursRange}},
+	"item-knn":      {new: newKNN(*NewItemKNN("adjusted-cosine")), space: Space{neighboursRange, shrinkageRange, {Name: "similarity", Values: []string{"adjusted-cosine", "pearson", "cosine"}}}},
+
+	"user-knn-baseline": {new: newKNN(*NewKNNBaseline(false)), space: Space{neighboursRange, shrinkageRange}},
+	"item-knn-baseline": {new: newKNN(*NewKNNBaseline(true)), space: Space{neighboursRange, shrinkageRange}},
+
+	"slope-one":          {new: newSlopeOne(false)},
+	"weighted-slope-one": {new: newSlopeOne(true)},
+
+	"svd":          {new: newSVD(*NewSVD()), space: sgdSpace},
+	"svdpp":        {new: newSVDPP(*NewSVDPP()), space: sgdSpace},
+	"als":          {new: newALS(*NewALS()), space: alsSpace},
+	"implicit-als": {new: newImplicitALS(*NewImplicitALS()), space: implicitALSSpace, ranking: true},
+
+	"global-mean": {new: newBaseline(*NewBaseline(GlobalMean))},
+	"user-mean":   {new: newBaseline(*NewBaseline(UserMean)), space: Space{userDampingRange}},
+	"item-mean":   {new: newBaseline(*NewBaseline(ItemMean)), space: Space{itemDampingRange}},
+	"baseline":    {new: newBaseline(*NewBaseline(UserItemBias)), space: Space{userDampingRange, itemDampingRange}},
+}
+
+// New returns an unfitted predictor for the named algorithm. Params override the algorithm's default
+// parameters; it is an error to pass a parameter the algorithm does not have.
+func New(name string, params Params) (Predictor, error) {
+	algorithm, ok := algorithms[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown algorithm %q (available: %v)", name, Algorithms())
+	}
+
+	predictor, err := algorithm.new(params)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	return predictor, nil
+}
+
+// Algorithms returns the sorted names of every algorithm that can be passed to New
+func Algorithms() []string {
+	names := make([]string, 0, len(algorithms))
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// RatingAlgorithms returns the name of every available algorithm that predicts ratings, in alphabetical
+// order. The others, such as implicit-als, predict scores that are only meaningful for ranking movies.
+func RatingAlgorithms() []string {
+	var names []string
+	for _, name := range Algorithms() {
+		if !algorithms[name].ranking {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// predictBatch makes a prediction for every pair, spread over one goroutine per CPU
+func predictBatch(p Predictor, pairs []Pair) []Prediction {
+	predictions, _ := PredictParallel(context.Background(), p, pairs, BatchOptions{})
+	return predictions
+}
@@ -0,0 +1,274 @@
+package cf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Ratings is a sparse store of the ratings users have given movies. Users and movies are identified by dense
+// indexes starting at 0, and the store grows as ratings for new users and movies are added. A (user, movie)
+// pair without a rating is missing; there are no sentinel values.
+type Ratings struct {
+	users [][]Entry           // users[user] holds every rating the user has made
+	items [][]Entry           // items[item] holds every rating of the movie
+	index map[pairKey]float64 // Every rating, for constant time lookup
+}
+
+// Entry is one rating in a user's or movie's adjacency list. Index is the movie in a user's list and the
+// user in a movie's list.
+type Entry struct {
+	Index int
+	Value float64
+}
+
+// Rating is a single (user, movie, rating) triple
+type Rating struct {
+	User  int
+	Item  int
+	Value float64
+}
+
+type pairKey uint64
+
+func keyOf(user, item int) pairKey {
+	return pairKey(uint64(uint32(user))<<32 | uint64(uint32(item)))
+}
+
+// NewRatings returns an empty rating store
+func NewRatings() *Ratings {
+	return &Ratings{index: make(map[pairKey]float64)}
+}
+
+// Add stores the rating the user gave the movie, replacing any earlier rating of the same pair
+func (r *Ratings) Add(user, item int, value float64) {
+	r.Grow(user+1, item+1)
+
+	key := keyOf(user, item)
+	if _, exists := r.index[key]; exists {
+		setEntry(r.users[user], item, value)
+		setEntry(r.items[item], user, value)
+	} else {
+		r.users[user] = append(r.users[user], Entry{Index: item, Value: value})
+		r.items[item] = append(r.items[item], Entry{Index: user, Value: value})
+	}
+	r.index[key] = value
+}
+
+// Grow makes sure the store counts at least the given number of users and movies, even if some of them
+// have no ratings
+func (r *Ratings) Grow(users, items int) {
+	for len(r.users) < users {
+		r.users = append(r.users, nil)
+	}
+	for len(r.items) < items {
+		r.items = append(r.items, nil)
+	}
+}
+
+// setEntry overwrites the value of the entry with the given index
+func setEntry(entries []Entry, index int, value float64) {
+	for idx := range entries {
+		if entries[idx].Index == index {
+			entries[idx].Value = value
+			return
+		}
+	}
+}
+
+// Get returns the rating the user gave the movie, and whether the user has rated it at all
+func (r *Ratings) Get(user, item int) (float64, bool) {
+	value, ok := r.index[keyOf(user, item)]
+	return value, ok
+}
+
+// Has reports whether the user has rated the movie
+func (r *Ratings) Has(user, item int) bool {
+	_, ok := r.index[keyOf(user, item)]
+	return ok
+}
+
+// NumUsers returns one more than the highest user index in the store
+func (r *Ratings) NumUsers() int {
+	return len(r.users)
+}
+
+// NumItems returns one more than the highest movie index in the store
+func (r *Ratings) NumItems() int {
+	return len(r.items)
+}
+
+// Len returns the number of ratings in the store
+func (r *Ratings) Len() int {
+	return len(r.index)
+}
+
+// UserRatings returns every rating the user has made, in the order they were added. The returned slice
+// must not be modified.
+func (r *Ratings) UserRatings(user int) []Entry {
+	if user < 0 || user >= len(r.users) {
+		return nil
+	}
+	return r.users[user]
+}
+
+// ItemRatings returns every rating of the movie, in the order they were added. The returned slice must
+// not be modified.
+func (r *Ratings) ItemRatings(item int) []Entry {
+	if item < 0 || item >= len(r.items) {
+		return nil
+	}
+	return r.items[item]
+}
+
+// All returns every rating in the store, grouped by user
+func (r *Ratings) All() []Rating {
+	all := make([]Rating, 0, r.Len())
+	for user, entries := range r.users {
+		for _, entry := range entries {
+			all = append(all, Rating{User: user, Item: entry.Index, Value: entry.Value})
+		}
+	}
+
+	return all
+}
+
+// Without returns a copy of the store with the given ratings removed, such as a held-out test set. The copy
+// keeps the store's number of users and movies even if some of them have no ratings left.
+func (r *Ratings) Without(removed []Rating) *Ratings {
+	skip := make(map[pairKey]bool, len(removed))
+	for _, rating := range removed {
+		skip[keyOf(rating.User, rating.Item)] = true
+	}
+
+	kept := NewRatings()
+	kept.Grow(r.NumUsers(), r.NumItems())
+	for user, entries := range r.users {
+		for _, entry := range entries {
+			if !skip[keyOf(user, entry.Index)] {
+				kept.Add(user, entry.Index, entry.Value)
+			}
+		}
+	}
+
+	return kept
+}
+
+// UserMean returns the average of all the ratings the user has made, or NaN if the user has not rated anything
+func (r *Ratings) UserMean(user int) float64 {
+	return mean(r.UserRatings(user))
+}
+
+// ItemMean returns the average of all the ratings of the movie, or NaN if nobody has rated it
+func (r *Ratings) ItemMean(item int) float64 {
+	return mean(r.ItemRatings(item))
+}
+
+func mean(entries []Entry) float64 {
+	var sumOfRatings float64 = 0
+	for _, entry := range entries {
+		sumOfRatings += entry.Value
+	}
+
+	return sumOfRatings / float64(len(entries))
+}
+
+// LoadDense reads a dense rating matrix such as train.txt from the named file
+func LoadDense(path string) (*Ratings, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadDense(file)
+}
+
+// ReadDense reads a dense rating matrix with one user per line and one whitespace separated rating per
+// movie, where 0 means "not rated". Every line must have the same number of columns.
+func ReadDense(reader io.Reader) (*Ratings, error) {
+	ratings := NewRatings()
+	noOfColumns := -1
+	user := 0
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if noOfColumns == -1 {
+			noOfColumns = len(fields)
+		} else if len(fields) != noOfColumns {
+			return nil, fmt.Errorf("line %d: expected %d ratings, got %d", lineNo, noOfColumns, len(fields))
+		}
+
+		for item, field := range fields {
+			value, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			if value != 0 {
+				ratings.Add(user, item, value)
+			}
+		}
+		user++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if ratings.Len() == 0 {
+		return nil, fmt.Errorf("no ratings found")
+	}
+
+	// Make sure users and movies without any ratings still count towards the size of the matrix
+	ratings.Grow(user, noOfColumns)
+
+	return ratings, nil
+}
+
+// forEachCoRatedItem calls fn with both users' ratings of every movie they have both rated
+func (r *Ratings) forEachCoRatedItem(user1, user2 int, fn func(item int, rating1, rating2 float64)) {
+	entries1, entries2 := r.UserRatings(user1), r.UserRatings(user2)
+
+	if len(entries1) <= len(entries2) {
+		for _, entry := range entries1 {
+			if rating2, ok := r.Get(user2, entry.Index); ok {
+				fn(entry.Index, entry.Value, rating2)
+			}
+		}
+	} else {
+		for _, entry := range entries2 {
+			if rating1, ok := r.Get(user1, entry.Index); ok {
+				fn(entry.Index, rating1, entry.Value)
+			}
+		}
+	}
+}
+
+// forEachCoRatingUser calls fn with both movies' ratings from every user who has rated both of them
+func (r *Ratings) forEachCoRatingUser(item1, item2 int, fn func(user int, rating1, rating2 float64)) {
+	entries1, entries2 := r.ItemRatings(item1), r.ItemRatings(item2)
+
+	if len(entries1) <= len(entries2) {
+		for _, entry := range entries1 {
+			if rating2, ok := r.Get(entry.Index, item2); ok {
+				fn(entry.Index, entry.Value, rating2)
+			}
+		}
+	} else {
+		for _, entry := range entries2 {
+			if rating1, ok := r.Get(entry.Index, item1); ok {
+				fn(entry.Index, rating1, entry.Value)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package cf
+
+import "sort"
+
+// Recommend returns up to n of the movies the user has not rated yet, ordered from the highest predicted
+// rating to the lowest. Movies the predictor could only fall back on the user's average for are left out.
+// The predictor must already be fitted on ratings.
+func Recommend(p Predictor, ratings *Ratings, user, n int) []Prediction {
+	var pairs []Pair
+	for item := 0; item < ratings.NumItems(); item++ {
+		if !ratings.Has(user, item) {
+			pairs = append(pairs, Pair{User: user, Item: item})
+		}
+	}
+
+	var recommendations []Prediction
+	for _, prediction := range p.PredictBatch(pairs) {
+		if prediction.OK {
+			recommendations = append(recommendations, prediction)
+		}
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Value > recommendations[j].Value
+	})
+
+	if len(recommendations) > n {
+		recommendations = recommendations[:n]
+	}
+
+	return recommendations
+}
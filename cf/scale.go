@@ -0,0 +1,116 @@
+package cf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Scale is a rating scale, such as the 1 to 5 stars of train.txt or the 0.5 to 5 half stars of MovieLens 10M
+type Scale struct {
+	Min  float64
+	Max  float64
+	Step float64 // Distance between neighbouring ratings on the scale
+}
+
+// DefaultScale is the whole star 1 to 5 scale used by train.txt, the test files and the Netflix Prize
+var DefaultScale = Scale{Min: 1, Max: 5, Step: 1}
+
+// Clamp returns the value limited to the scale's range
+func (s Scale) Clamp(value float64) float64 {
+	return math.Max(s.Min, math.Min(s.Max, value))
+}
+
+// Round returns the rating on the scale closest to the value
+func (s Scale) Round(value float64) float64 {
+	return s.Clamp(s.Min + math.Round((value-s.Min)/s.Step)*s.Step)
+}
+
+// String formats the scale in the form ParseScale reads
+func (s Scale) String() string {
+	format := func(value float64) string { return strconv.FormatFloat(value, 'g', -1, 64) }
+	return format(s.Min) + ":" + format(s.Max) + ":" + format(s.Step)
+}
+
+// ParseScale parses a scale written as "min:max" or "min:max:step"; the step defaults to 1
+func ParseScale(text string) (Scale, error) {
+	fields := strings.Split(text, ":")
+	if len(fields) != 2 && len(fields) != 3 {
+		return Scale{}, fmt.Errorf("invalid scale %q (expected min:max or min:max:step)", text)
+	}
+
+	values := []float64{0, 0, 1}
+	for idx, field := range fields {
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return Scale{}, fmt.Errorf("invalid scale %q: %v", text, err)
+		}
+		values[idx] = value
+	}
+
+	scale := Scale{Min: values[0], Max: values[1], Step: values[2]}
+	if scale.Max <= scale.Min || scale.Step <= 0 {
+		return Scale{}, fmt.Errorf("invalid scale %q: max must be above min and step must be positive", text)
+	}
+
+	return scale, nil
+}
+
+// OutputPolicy decides how predicted values are fitted to the rating scale before they are used. Predictors
+// themselves always produce unrounded values, which may fall outside the scale.
+type OutputPolicy int
+
+const (
+	// OutputRaw leaves predictions exactly as the predictor made them
+	OutputRaw OutputPolicy = iota
+
+	// OutputClamp limits predictions to the scale's range but keeps their fractional part
+	OutputClamp
+
+	// OutputRound rounds predictions to the nearest rating on the scale, as the course's result files require
+	OutputRound
+)
+
+var outputPolicyNames = []string{"raw", "clamp", "round"}
+
+// String returns the policy's name
+func (o OutputPolicy) String() string {
+	if o < 0 || int(o) >= len(outputPolicyNames) {
+		return fmt.Sprintf("OutputPolicy(%d)", int(o))
+	}
+	return outputPolicyNames[o]
+}
+
+// ParseOutputPolicy returns the policy with the given name: "raw", "clamp" or "round"
+func ParseOutputPolicy(name string) (OutputPolicy, error) {
+	for idx, policyName := range outputPolicyNames {
+		if name == policyName {
+			return OutputPolicy(idx), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown output policy %q (available: %s)", name, strings.Join(outputPolicyNames, ", "))
+}
+
+// Apply returns the value fitted to the scale according to the policy. NaN values are returned unchanged.
+func (o OutputPolicy) Apply(value float64, scale Scale) float64 {
+	if math.IsNaN(value) {
+		return value
+	}
+
+	switch o {
+	case OutputClamp:
+		return scale.Clamp(value)
+	case OutputRound:
+		return scale.Round(value)
+	default:
+		return value
+	}
+}
+
+// ApplyOutput fits every prediction's value to the scale according to the policy
+func ApplyOutput(predictions []Prediction, policy OutputPolicy, scale Scale) {
+	for idx := range predictions {
+		predictions[idx].Value = policy.Apply(predictions[idx].Value, scale)
+	}
+}
@@ -0,0 +1,318 @@
+package cf
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Vectors views a rating store as one rating vector per user, for user-based similarity, or one per movie,
+// for item-based similarity. It caches the statistics the similarity functions need.
+type Vectors struct {
+	ratings   *Ratings
+	itemBased bool
+	scale     Scale
+
+	means      []float64 // Mean rating of every vector
+	otherMeans []float64 // Mean rating of every movie (user-based) or user (item-based)
+
+	ranksOnce sync.Once
+	ranks     *Ratings  // Ratings replaced by their rank within their vector, for Spearman
+	rankMeans []float64 // Mean rank of every vector
+}
+
+// NewVectors returns user vectors, or movie vectors when itemBased is set, over the ratings
+func NewVectors(ratings *Ratings, itemBased bool, scale Scale) *Vectors {
+	v := &Vectors{ratings: ratings, itemBased: itemBased, scale: scale}
+
+	v.means = make([]float64, v.Len())
+	for a := range v.means {
+		v.means[a] = mean(v.Entries(a))
+	}
+
+	if itemBased {
+		v.otherMeans = make([]float64, ratings.NumUsers())
+		for user := range v.otherMeans {
+			v.otherMeans[user] = ratings.UserMean(user)
+		}
+	} else {
+		v.otherMeans = make([]float64, ratings.NumItems())
+		for item := range v.otherMeans {
+			v.otherMeans[item] = ratings.ItemMean(item)
+		}
+	}
+
+	return v
+}
+
+// Len returns the number of vectors
+func (v *Vectors) Len() int {
+	if v.itemBased {
+		return v.ratings.NumItems()
+	}
+	return v.ratings.NumUsers()
+}
+
+// Entries returns the ratings in vector a
+func (v *Vectors) Entries(a int) []Entry {
+	if v.itemBased {
+		return v.ratings.ItemRatings(a)
+	}
+	return v.ratings.UserRatings(a)
+}
+
+// Mean returns the mean rating in vector a
+func (v *Vectors) Mean(a int) float64 {
+	return v.means[a]
+}
+
+// OtherMean returns the mean rating of the movie (for user vectors) or user (for movie vectors) at index
+func (v *Vectors) OtherMean(index int) float64 {
+	return v.otherMeans[index]
+}
+
+// Scale returns the rating scale
+func (v *Vectors) Scale() Scale {
+	return v.scale
+}
+
+// ForEachCommon calls fn for every movie (or user) rated in both vector a and vector b
+func (v *Vectors) ForEachCommon(a, b int, fn func(index int, ratingA, ratingB float64)) {
+	forEachCommon(v.ratings, v.itemBased, a, b, fn)
+}
+
+func forEachCommon(ratings *Ratings, itemBased bool, a, b int, fn func(index int, ratingA, ratingB float64)) {
+	if itemBased {
+		ratings.forEachCoRatingUser(a, b, fn)
+	} else {
+		ratings.forEachCoRatedItem(a, b, fn)
+	}
+}
+
+// rankVectors returns the store of ranks used for Spearman correlation, building it on first use. Each
+// rating is replaced by its rank among the ratings in its vector, with tied ratings sharing their average rank.
+func (v *Vectors) rankVectors() (*Ratings, []float64) {
+	v.ranksOnce.Do(func() {
+		v.ranks = NewRatings()
+		v.ranks.Grow(v.ratings.NumUsers(), v.ratings.NumItems())
+		v.rankMeans = make([]float64, v.Len())
+
+		for a := 0; a < v.Len(); a++ {
+			entries := append([]Entry(nil), v.Entries(a)...)
+			sort.SliceStable(entries, func(i, j int) bool { return entries[i].Value < entries[j].Value })
+
+			for start := 0; start < len(entries); {
+				end := start
+				for end < len(entries) && entries[end].Value == entries[start].Value {
+					end++
+				}
+
+				rank := float64(start+end+1) / 2 // Average of the 1-based ranks start+1 to end
+				for _, entry := range entries[start:end] {
+					if v.itemBased {
+						v.ranks.Add(entry.Index, a, rank)
+					} else {
+						v.ranks.Add(a, entry.Index, rank)
+					}
+				}
+				start = end
+			}
+
+			v.rankMeans[a] = float64(len(entries)+1) / 2
+		}
+	})
+
+	return v.ranks, v.rankMeans
+}
+
+// Similarity is how similar two vectors are, along with the number of ratings the score was computed from
+type Similarity struct {
+	Score     float64 // Usually between -1 and 1
+	CoRatings int     // Number of movies (or users) rated in both vectors
+}
+
+// SimilarityFunc returns how similar vectors a and b are
+type SimilarityFunc func(v *Vectors, a, b int) Similarity
+
+// SimilarityModifier adjusts a similarity, typically to reduce the trust placed in scores computed from few
+// co-ratings. Modifiers work with any similarity function; see Modify.
+type SimilarityModifier func(s Similarity) Similarity
+
+// Modify returns a similarity function that applies the modifiers, in order, to every similarity fn returns
+func Modify(fn SimilarityFunc, modifiers ...SimilarityModifier) SimilarityFunc {
+	if len(modifiers) == 0 {
+		return fn
+	}
+	return func(v *Vectors, a, b int) Similarity {
+		similarity := fn(v, a, b)
+		for _, modify := range modifiers {
+			similarity = modify(similarity)
+		}
+		return similarity
+	}
+}
+
+// SignificanceWeighting returns Herlocker et al.'s significance weighting, which scales similarities computed
+// from fewer than n co-ratings by min(co-ratings, n) / n. An n of 0 disables it.
+func SignificanceWeighting(n int) SimilarityModifier {
+	return func(s Similarity) Similarity {
+		if n > 0 && s.CoRatings < n {
+			s.Score *= float64(s.CoRatings) / float64(n)
+		}
+		return s
+	}
+}
+
+// Shrinkage returns Bell and Koren's shrinkage, which scales every similarity by co-ratings / (co-ratings + lambda)
+// so that scores computed from few co-ratings are pulled towards 0. A lambda of 0 disables it.
+func Shrinkage(lambda float64) SimilarityModifier {
+	return func(s Similarity) Similarity {
+		if lambda > 0 {
+			s.Score *= float64(s.CoRatings) / (float64(s.CoRatings) + lambda)
+		}
+		return s
+	}
+}
+
+// similarities is the registry of similarity functions, by name
+var similarities = map[string]SimilarityFunc{
+	"cosine":              CosineSimilarity,
+	"pearson":             PearsonSimilarity,
+	"adjusted-cosine":     AdjustedCosineSimilarity,
+	"spearman":            SpearmanSimilarity,
+	"jaccard":             JaccardSimilarity,
+	"msd":                 MSDSimilarity,
+	"constrained-pearson": ConstrainedPearsonSimilarity,
+}
+
+// RegisterSimilarity adds a similarity function to the registry, replacing any function with the same name
+func RegisterSimilarity(name string, fn SimilarityFunc) {
+	similarities[name] = fn
+}
+
+// LookupSimilarity returns the named similarity function from the registry
+func LookupSimilarity(name string) (SimilarityFunc, error) {
+	fn, ok := similarities[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown similarity %q (available: %v)", name, Similarities())
+	}
+	return fn, nil
+}
+
+// Similarities returns the sorted names of every similarity function in the registry
+func Similarities() []string {
+	names := make([]string, 0, len(similarities))
+	for name := range similarities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CosineSimilarity is the cosine of the angle between the two vectors' raw ratings, over the ratings they
+// have in common
+func CosineSimilarity(v *Vectors, a, b int) Similarity {
+	return centeredCosine(v, a, b, func(int, int) float64 { return 0 })
+}
+
+// PearsonSimilarity is the Pearson correlation between the two vectors over the ratings they have in common,
+// with each vector centered on the mean of all of its ratings
+func PearsonSimilarity(v *Vectors, a, b int) Similarity {
+	return centeredCosine(v, a, b, func(vector, _ int) float64 { return v.means[vector] })
+}
+
+// AdjustedCosineSimilarity is the cosine similarity after every rating is centered on the mean rating of the
+// other dimension: the rating user's mean for movie vectors, and the rated movie's mean for user vectors.
+// For item-based algorithms this removes the differences between generous and harsh raters.
+func AdjustedCosineSimilarity(v *Vectors, a, b int) Similarity {
+	center := func(_, index int) float64 { return v.otherMeans[index] }
+	return centeredCosine(v, a, b, center)
+}
+
+// ConstrainedPearsonSimilarity is the Pearson correlation with every rating centered on the middle of the
+// rating scale instead of the vector's mean, so that only ratings on the same side of neutral agree
+func ConstrainedPearsonSimilarity(v *Vectors, a, b int) Similarity {
+	midpoint := (v.scale.Min + v.scale.Max) / 2
+	center := func(int, int) float64 { return midpoint }
+	return centeredCosine(v, a, b, center)
+}
+
+// SpearmanSimilarity is the Pearson correlation between the ranks of the two vectors' ratings
+func SpearmanSimilarity(v *Vectors, a, b int) Similarity {
+	ranks, rankMeans := v.rankVectors()
+	var common int
+
+	var summation1 float64 = 0 // Represents: summation( (Rank_A - Avg_Rank_A) * (Rank_B - Avg_Rank_B) )
+	var summation2 float64 = 0 // Represents: summation( squared(Rank_A - Avg_Rank_A) )
+	var summation3 float64 = 0 // Represents: summation( squared(Rank_B - Avg_Rank_B) )
+
+	forEachCommon(ranks, v.itemBased, a, b, func(_ int, rankA, rankB float64) {
+		normalizedA, normalizedB := rankA-rankMeans[a], rankB-rankMeans[b]
+		summation1 += normalizedA * normalizedB
+		summation2 += normalizedA * normalizedA
+		summation3 += normalizedB * normalizedB
+		common++
+	})
+
+	return Similarity{Score: finite(summation1 / (math.Sqrt(summation2) * math.Sqrt(summation3))), CoRatings: common}
+}
+
+// JaccardSimilarity is the number of movies (or users) both vectors have rated divided by the number either
+// has rated. It ignores the rating values.
+func JaccardSimilarity(v *Vectors, a, b int) Similarity {
+	var common int
+	v.ForEachCommon(a, b, func(int, float64, float64) { common++ })
+
+	union := len(v.Entries(a)) + len(v.Entries(b)) - common
+	if union == 0 {
+		return Similarity{}
+	}
+	return Similarity{Score: float64(common) / float64(union), CoRatings: common}
+}
+
+// MSDSimilarity is 1 / (1 + msd), where msd is the mean squared difference between the two vectors' ratings
+// over the ratings they have in common. Vectors with nothing in common have a similarity of 0.
+func MSDSimilarity(v *Vectors, a, b int) Similarity {
+	var sumOfSquaredDifferences float64 = 0
+	var common int
+
+	v.ForEachCommon(a, b, func(_ int, ratingA, ratingB float64) {
+		sumOfSquaredDifferences += (ratingA - ratingB) * (ratingA - ratingB)
+		common++
+	})
+
+	if common == 0 {
+		return Similarity{}
+	}
+	return Similarity{Score: 1 / (1 + sumOfSquaredDifferences/float64(common)), CoRatings: common}
+}
+
+// centeredCosine returns the cosine similarity between the two vectors over the ratings they have in common,
+// after subtracting center(vector, index) from every rating
+func centeredCosine(v *Vectors, a, b int, center func(vector, index int) float64) Similarity {
+	var common int
+	var summation1 float64 = 0 // Represents: summation( (Rating_A - Center_A) * (Rating_B - Center_B) )
+	var summation2 float64 = 0 // Represents: summation( squared(Rating_A - Center_A) )
+	var summation3 float64 = 0 // Represents: summation( squared(Rating_B - Center_B) )
+
+	v.ForEachCommon(a, b, func(index int, ratingA, ratingB float64) {
+		normalizedA := ratingA - center(a, index)
+		normalizedB := ratingB - center(b, index)
+
+		summation1 += normalizedA * normalizedB
+		summation2 += normalizedA * normalizedA
+		summation3 += normalizedB * normalizedB
+		common++
+	})
+
+	return Similarity{Score: finite(summation1 / (math.Sqrt(summation2) * math.Sqrt(summation3))), CoRatings: common}
+}
+
+// finite returns 0 in place of NaN and infinite similarities, which arise when a vector has no spread
+func finite(similarity float64) float64 {
+	if math.IsNaN(similarity) || math.IsInf(similarity, 0) {
+		return 0
+	}
+	return similarity
+}
@@ -0,0 +1,173 @@
+package cf
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SlopeOne is Lemire and Maclachlan's Slope One, an item-based predictor that is cheap to fit and to keep
+// up to date. For every pair of movies it keeps the average difference between their ratings by the users
+// who rated both. A user's rating of movie j is predicted from each movie i the user rated as r_ui plus the
+// average difference between j and i, and the predictions are averaged. Weighted Slope One weights each by
+// the number of users the difference was averaged over.
+//
+// The table of differences can be updated as ratings arrive with Add, without fitting again. Add may be
+// called at the same time as predictions are made. The predictor keeps its own copy of the ratings it is
+// fitted on, so the ratings Add stores never change the caller's.
+type SlopeOne struct {
+	Weighted bool
+
+	mu         sync.RWMutex
+	ratings    *Ratings            // A copy of the ratings the predictor was fitted on, along with those added since
+	mean       float64             // Average of the ratings the predictor was fitted on, the fallback for unknown users
+	deviations []map[int]deviation // deviations[a][b], for a < b, sums the differences r_a - r_b
+}
+
+// deviation is the sum of the differences between the ratings of two movies and the number of users it
+// is summed over
+type deviation struct {
+	sum   float64
+	count int
+}
+
+// NewSlopeOne returns a Slope One predictor, weighted if weighted is set
+func NewSlopeOne(weighted bool) *SlopeOne {
+	return &SlopeOne{Weighted: weighted}
+}
+
+// newSlopeOne returns a constructor for Slope One predictors, which have no parameters
+func newSlopeOne(weighted bool) func(params Params) (Predictor, error) {
+	return func(params Params) (Predictor, error) {
+		if err := params.check(); err != nil {
+			return nil, err
+		}
+		return NewSlopeOne(weighted), nil
+	}
+}
+
+// Fit implements Predictor
+func (p *SlopeOne) Fit(ratings *Ratings) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.ratings = ratings.Without(nil)
+	p.mean = globalMean(ratings)
+	p.deviations = make([]map[int]deviation, ratings.NumItems())
+
+	for user := 0; user < ratings.NumUsers(); user++ {
+		entries := ratings.UserRatings(user)
+		for idx, a := range entries {
+			for _, b := range entries[idx+1:] {
+				p.addDifference(a.Index, b.Index, a.Value-b.Value, 1)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Add stores a new rating, or replaces an earlier rating of the same pair, and updates the table of
+// differences to match. It is an error to add a rating before the predictor is fitted.
+func (p *SlopeOne) Add(user, item int, value float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ratings == nil {
+		return errors.New("slope one predictor must be fitted before ratings are added")
+	}
+	if user < 0 || item < 0 {
+		return fmt.Errorf("user and movie indexes must not be negative, got %d and %d", user, item)
+	}
+
+	if item >= len(p.deviations) {
+		p.deviations = append(p.deviations, make([]map[int]deviation, item+1-len(p.deviations))...)
+	}
+
+	old, replaced := p.ratings.Get(user, item)
+	for _, other := range p.ratings.UserRatings(user) {
+		if other.Index == item {
+			continue
+		}
+		if replaced {
+			p.addDifference(item, other.Index, value-old, 0)
+		} else {
+			p.addDifference(item, other.Index, value-other.Value, 1)
+		}
+	}
+
+	p.ratings.Add(user, item, value)
+	return nil
+}
+
+// addDifference adds diff, a difference r_a - r_b, to the sum for movies a and b and count to its count
+func (p *SlopeOne) addDifference(a, b int, diff float64, count int) {
+	if a > b {
+		a, b, diff = b, a, -diff
+	}
+	if p.deviations[a] == nil {
+		p.deviations[a] = map[int]deviation{}
+	}
+
+	d := p.deviations[a][b]
+	d.sum += diff
+	d.count += count
+	p.deviations[a][b] = d
+}
+
+// deviation returns the average difference r_a - r_b over the users who rated both movies, and how many
+// users that is
+func (p *SlopeOne) deviation(a, b int) (float64, int) {
+	sign := 1.0
+	if a > b {
+		a, b, sign = b, a, -1
+	}
+
+	d := p.deviations[a][b]
+	if d.count == 0 {
+		return 0, 0
+	}
+	return sign * d.sum / float64(d.count), d.count
+}
+
+// Predict implements Predictor. Predictions for movies that no user rated along with any of the user's
+// movies fall back on the user's average rating, or on the average of every rating for users with none.
+func (p *SlopeOne) Predict(user, item int) Prediction {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var summation1 float64 = 0 // Represents: summation( (Deviation + Users_Rating) * Weight )
+	var summation2 float64 = 0 // Represents: summation( Weight )
+
+	if item >= 0 && item < len(p.deviations) {
+		for _, entry := range p.ratings.UserRatings(user) {
+			if entry.Index == item {
+				continue
+			}
+			deviation, count := p.deviation(item, entry.Index)
+			if count == 0 {
+				continue
+			}
+
+			weight := 1.0
+			if p.Weighted {
+				weight = float64(count)
+			}
+			summation1 += (deviation + entry.Value) * weight
+			summation2 += weight
+		}
+	}
+
+	if summation2 == 0 {
+		if len(p.ratings.UserRatings(user)) == 0 {
+			return Prediction{User: user, Item: item, Value: p.mean, OK: false}
+		}
+		return Prediction{User: user, Item: item, Value: p.ratings.UserMean(user), OK: false}
+	}
+	return Prediction{User: user, Item: item, Value: summation1 / summation2, OK: true}
+}
+
+// PredictBatch implements Predictor
+func (p *SlopeOne) PredictBatch(pairs []Pair) []Prediction {
+	return predictBatch(p, pairs)
+}
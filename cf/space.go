@@ -0,0 +1,133 @@
+package cf
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+)
+
+// Range is the set of values a hyperparameter search tries for one algorithm parameter
+type Range struct {
+	Name string
+
+	// Values are the values grid search tries, and the values random search picks from when Min and Max
+	// do not describe a range
+	Values []string
+
+	// Random search samples numbers from [Min, Max] when Max > Min, log-uniformly if Log is set and
+	// rounded to whole numbers if Integer is set
+	Min, Max float64
+	Log      bool
+	Integer  bool
+}
+
+// Sample returns a random value from the range
+func (r Range) Sample(random *rand.Rand) string {
+	if r.Max <= r.Min {
+		return r.Values[random.Intn(len(r.Values))]
+	}
+
+	value := r.Min + random.Float64()*(r.Max-r.Min)
+	if r.Log {
+		value = math.Exp(math.Log(r.Min) + random.Float64()*(math.Log(r.Max)-math.Log(r.Min)))
+	}
+	if r.Integer {
+		value = math.Round(value)
+	}
+	return strconv.FormatFloat(value, 'g', 6, 64)
+}
+
+// Space is an algorithm's declared hyperparameter space: the parameters worth tuning and the values to try
+type Space []Range
+
+// Without returns the space without the ranges of the named parameters, for parameters that are fixed
+func (s Space) Without(names ...string) Space {
+	var result Space
+	for _, r := range s {
+		fixed := false
+		for _, name := range names {
+			if r.Name == name {
+				fixed = true
+				break
+			}
+		}
+		if !fixed {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// Grid returns every combination of the ranges' values. An empty space has a single, empty combination.
+func (s Space) Grid() []Params {
+	grid := []Params{{}}
+	for _, r := range s {
+		next := make([]Params, 0, len(grid)*len(r.Values))
+		for _, params := range grid {
+			for _, value := range r.Values {
+				combination := Params{r.Name: value}
+				for name, other := range params {
+					combination[name] = other
+				}
+				next = append(next, combination)
+			}
+		}
+		grid = next
+	}
+	return grid
+}
+
+// Sample returns a random point in the space
+func (s Space) Sample(random *rand.Rand) Params {
+	params := Params{}
+	for _, r := range s {
+		params[r.Name] = r.Sample(random)
+	}
+	return params
+}
+
+// SpaceOf returns the declared hyperparameter space of the named algorithm
+func SpaceOf(name string) (Space, error) {
+	algorithm, ok := algorithms[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown algorithm %q (available: %v)", name, Algorithms())
+	}
+	return algorithm.space, nil
+}
+
+// The ranges shared by the neighbourhood algorithms
+var (
+	neighboursRange   = Range{Name: "neighbours", Values: []string{"10", "20", "30", "40", "50"}, Min: 5, Max: 80, Integer: true}
+	caseRange         = Range{Name: "p", Values: []string{"1", "1.5", "2", "2.5", "3", "3.5"}, Min: 1, Max: 4}
+	weightingRange    = Range{Name: "weighting", Values: weightingNames}
+	significanceRange = Range{Name: "significance", Values: []string{"0", "25", "50"}, Min: 0, Max: 100, Integer: true}
+	shrinkageRange    = Range{Name: "shrinkage", Values: []string{"0", "10", "100"}, Min: 1, Max: 500, Log: true}
+
+	// The ranges of the baselines
+	userDampingRange = Range{Name: "user-damping", Values: []string{"0", "5", "15", "25"}, Min: 0, Max: 50}
+	itemDampingRange = Range{Name: "item-damping", Values: []string{"0", "5", "10", "25"}, Min: 0, Max: 50}
+
+	// The ranges shared by the gradient descent factorizations
+	sgdSpace = Space{
+		{Name: "factors", Values: []string{"10", "20", "50", "100"}, Min: 5, Max: 200, Integer: true, Log: true},
+		{Name: "epochs", Values: []string{"20", "40"}, Min: 10, Max: 60, Integer: true},
+		{Name: "learning-rate", Values: []string{"0.002", "0.005", "0.01"}, Min: 0.001, Max: 0.02, Log: true},
+		{Name: "regularization", Values: []string{"0.02", "0.05", "0.1"}, Min: 0.005, Max: 0.2, Log: true},
+	}
+
+	// The ranges shared by the alternating least squares factorizations
+	alsSpace = Space{
+		{Name: "factors", Values: []string{"10", "20", "50"}, Min: 5, Max: 100, Integer: true, Log: true},
+		{Name: "iterations", Values: []string{"10", "20"}, Min: 5, Max: 30, Integer: true},
+		{Name: "regularization", Values: []string{"0.02", "0.05", "0.1", "0.2"}, Min: 0.01, Max: 0.5, Log: true},
+	}
+
+	// The ranges of implicit ALS, whose regularization is not weighted by the number of interactions
+	implicitALSSpace = Space{
+		alsSpace[0],
+		alsSpace[1],
+		{Name: "regularization", Values: []string{"0.01", "0.1", "1", "10"}, Min: 0.001, Max: 100, Log: true},
+		{Name: "alpha", Values: []string{"1", "10", "40"}, Min: 0.5, Max: 100, Log: true},
+	}
+)
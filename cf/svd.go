@@ -0,0 +1,129 @@
+package cf
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// SVD is Funk's biased matrix factorization, trained by stochastic gradient descent. Every rating r of
+// movie i by user u is approximated by mean + b_u + b_i + q_i · p_u, where mean is the average of every
+// rating, b_u and b_i are the user's and the movie's biases and p_u and q_i are their latent factors.
+// Each epoch visits the ratings in a random order, seeded by Seed, so fitting is deterministic.
+type SVD struct {
+	Factors        int     // Number of latent factors
+	Epochs         int     // Number of passes over the ratings
+	LearningRate   float64 // Step size of every gradient descent update
+	Regularization float64 // Penalty on the size of the biases and factors
+	InitStd        float64 // Standard deviation of the normally distributed initial factors
+	Biased         bool    // Learn the user and movie biases; otherwise only the mean and factors are used
+	Seed           int64
+
+	model *factorModel
+}
+
+// NewSVD returns a biased SVD with the commonly used defaults of 100 factors trained for 20 epochs with a
+// learning rate of 0.005 and regularization of 0.02
+func NewSVD() *SVD {
+	return &SVD{Factors: 100, Epochs: 20, LearningRate: 0.005, Regularization: 0.02, InitStd: 0.1, Biased: true}
+}
+
+// sgdParamNames are the parameters sgdParams reads
+var sgdParamNames = []string{"factors", "epochs", "learning-rate", "regularization", "init-std", "seed"}
+
+// newSVD returns a constructor for SVD predictors that start out as a copy of def
+func newSVD(def SVD) func(params Params) (Predictor, error) {
+	return func(params Params) (Predictor, error) {
+		if err := params.check(append(sgdParamNames, "biased")...); err != nil {
+			return nil, err
+		}
+
+		p := def
+		if err := p.sgdParams(params); err != nil {
+			return nil, err
+		}
+		var err error
+		if p.Biased, err = params.Bool("biased", p.Biased); err != nil {
+			return nil, err
+		}
+
+		return &p, nil
+	}
+}
+
+// sgdParams applies the parameters shared by the gradient descent factorizations
+func (p *SVD) sgdParams(params Params) error {
+	var err error
+	if p.Factors, err = params.Int("factors", p.Factors); err != nil {
+		return err
+	}
+	if p.Epochs, err = params.Int("epochs", p.Epochs); err != nil {
+		return err
+	}
+	if p.LearningRate, err = params.Float("learning-rate", p.LearningRate); err != nil {
+		return err
+	}
+	if p.Regularization, err = params.Float("regularization", p.Regularization); err != nil {
+		return err
+	}
+	if p.InitStd, err = params.Float("init-std", p.InitStd); err != nil {
+		return err
+	}
+	seed, err := params.Int("seed", int(p.Seed))
+	p.Seed = int64(seed)
+	return err
+}
+
+// validate checks the training settings
+func (p *SVD) validate() error {
+	if p.Factors < 1 {
+		return fmt.Errorf("factors must be at least 1, got %d", p.Factors)
+	}
+	if p.Epochs < 0 || p.LearningRate <= 0 || p.Regularization < 0 || p.InitStd < 0 {
+		return fmt.Errorf("epochs, regularization and init-std must not be negative and the learning rate must be positive")
+	}
+	return nil
+}
+
+// Fit implements Predictor
+func (p *SVD) Fit(ratings *Ratings) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+
+	random := rand.New(rand.NewSource(p.Seed))
+	m := newFactorModel(ratings, p.Factors, p.InitStd, random)
+	all := ratings.All()
+
+	for epoch := 0; epoch < p.Epochs; epoch++ {
+		random.Shuffle(len(all), func(i, j int) { all[i], all[j] = all[j], all[i] })
+
+		for _, rating := range all {
+			userFactors, itemFactors := m.userFactors[rating.User], m.itemFactors[rating.Item]
+			residual := rating.Value - (m.mean + m.userBias[rating.User] + m.itemBias[rating.Item] + dot(userFactors, itemFactors))
+
+			if p.Biased {
+				m.userBias[rating.User] += p.LearningRate * (residual - p.Regularization*m.userBias[rating.User])
+				m.itemBias[rating.Item] += p.LearningRate * (residual - p.Regularization*m.itemBias[rating.Item])
+			}
+			for factor := range userFactors {
+				userFactor, itemFactor := userFactors[factor], itemFactors[factor]
+				userFactors[factor] += p.LearningRate * (residual*itemFactor - p.Regularization*userFactor)
+				itemFactors[factor] += p.LearningRate * (residual*userFactor - p.Regularization*itemFactor)
+			}
+		}
+	}
+
+	p.model = m
+	return nil
+}
+
+// Predict implements Predictor. Users and movies with no ratings are predicted the mean plus whichever
+// bias is known, and the prediction is not OK.
+func (p *SVD) Predict(user, item int) Prediction {
+	return p.model.predict(user, item, p.model.userFactorsOf(user))
+}
+
+// PredictBatch implements Predictor
+func (p *SVD) PredictBatch(pairs []Pair) []Prediction {
+	return predictBatch(p, pairs)
+}
@@ -0,0 +1,199 @@
+package cf
+
+import (
+	"math"
+	"math/rand"
+)
+
+// SVDPP is Koren's SVD++, an SVD whose user factors also take implicit feedback into account: the
+// set N(u) of movies the user is known to have rated, whether or not the ratings themselves are known. A
+// rating is approximated by mean + b_u + b_i + q_i · (p_u + |N(u)|^-½ · summation( y_j for j in N(u) )),
+// where every movie j has a second set of factors y_j. N(u) holds the user's training ratings and any pairs
+// given to AddImplicit, so users with few known ratings, such as those in a given-N test file, are
+// described by every movie they rated.
+//
+// Training visits the users in a random order and each user's ratings in a random order. The implicit
+// factors of a user's movies are updated once per visit, with the gradients accumulated over the user's
+// ratings, rather than after every rating, which costs a fraction as much. This only approximates per-rating
+// gradient descent: the regularization of y_j is also applied once per visit rather than once per rating, so
+// the effective regularization of the implicit factors is weaker than Regularization. As with SVD, the user
+// and movie biases are only learnt when Biased is set.
+type SVDPP struct {
+	SVD
+
+	extra           []Pair      // Implicit feedback beyond the training ratings
+	implicitFactors [][]float64 // y_j of every movie
+	userVectors     [][]float64 // p_u + |N(u)|^-½ · summation( y_j ) of every user, once fitted
+}
+
+// NewSVDPP returns an SVD++ with the commonly used defaults of 20 factors trained for 20 epochs with a
+// learning rate of 0.007 and regularization of 0.02
+func NewSVDPP() *SVDPP {
+	return &SVDPP{SVD: SVD{Factors: 20, Epochs: 20, LearningRate: 0.007, Regularization: 0.02, InitStd: 0.1, Biased: true}}
+}
+
+// newSVDPP returns a constructor for SVD++ predictors that start out as a copy of def
+func newSVDPP(def SVDPP) func(params Params) (Predictor, error) {
+	return func(params Params) (Predictor, error) {
+		if err := params.check(append(sgdParamNames, "biased")...); err != nil {
+			return nil, err
+		}
+
+		p := def
+		if err := p.sgdParams(params); err != nil {
+			return nil, err
+		}
+		var err error
+		if p.Biased, err = params.Bool("biased", p.Biased); err != nil {
+			return nil, err
+		}
+
+		return &p, nil
+	}
+}
+
+// AddImplicit implements ImplicitPredictor
+func (p *SVDPP) AddImplicit(pairs []Pair) {
+	p.extra = append(p.extra, pairs...)
+}
+
+// Fit implements Predictor
+func (p *SVDPP) Fit(ratings *Ratings) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+
+	random := rand.New(rand.NewSource(p.Seed))
+	m := newFactorModel(ratings, p.Factors, p.InitStd, random)
+	p.implicitFactors = randomFactors(ratings.NumItems(), p.Factors, p.InitStd, random)
+	implicit := p.implicitItems(ratings)
+
+	users := make([]int, ratings.NumUsers())
+	for user := range users {
+		users[user] = user
+	}
+	userImplicit := make([]float64, p.Factors) // Represents: |N(u)|^-½ · summation( y_j )
+	gradient := make([]float64, p.Factors)     // Represents: summation( Error * q_i ) over the user's ratings
+	userVector := make([]float64, p.Factors)
+
+	for epoch := 0; epoch < p.Epochs; epoch++ {
+		random.Shuffle(len(users), func(i, j int) { users[i], users[j] = users[j], users[i] })
+
+		for _, user := range users {
+			entries := append([]Entry(nil), ratings.UserRatings(user)...)
+			if len(entries) == 0 {
+				continue
+			}
+			random.Shuffle(len(entries), func(i, j int) { entries[i], entries[j] = entries[j], entries[i] })
+
+			norm := p.implicitSum(implicit[user], userImplicit)
+			for factor := range gradient {
+				gradient[factor] = 0
+			}
+
+			userFactors := m.userFactors[user]
+			for _, entry := range entries {
+				itemFactors := m.itemFactors[entry.Index]
+				for factor := range userVector {
+					userVector[factor] = userFactors[factor] + userImplicit[factor]
+				}
+				residual := entry.Value - (m.mean + m.userBias[user] + m.itemBias[entry.Index] + dot(userVector, itemFactors))
+
+				if p.Biased {
+					m.userBias[user] += p.LearningRate * (residual - p.Regularization*m.userBias[user])
+					m.itemBias[entry.Index] += p.LearningRate * (residual - p.Regularization*m.itemBias[entry.Index])
+				}
+				for factor := range userFactors {
+					userFactor, itemFactor := userFactors[factor], itemFactors[factor]
+					userFactors[factor] += p.LearningRate * (residual*itemFactor - p.Regularization*userFactor)
+					itemFactors[factor] += p.LearningRate * (residual*userVector[factor] - p.Regularization*itemFactor)
+					gradient[factor] += residual * itemFactor
+				}
+			}
+
+			for _, item := range implicit[user] {
+				implicitFactors := p.implicitFactors[item]
+				for factor := range implicitFactors {
+					implicitFactors[factor] += p.LearningRate * (norm*gradient[factor] - p.Regularization*implicitFactors[factor])
+				}
+			}
+		}
+	}
+
+	p.userVectors = make([][]float64, ratings.NumUsers())
+	for user := range p.userVectors {
+		p.userVectors[user] = make([]float64, p.Factors)
+		p.implicitSum(implicit[user], p.userVectors[user])
+		for factor, userFactor := range m.userFactors[user] {
+			p.userVectors[user][factor] += userFactor
+		}
+	}
+
+	p.SVD.model = m
+	return nil
+}
+
+// implicitItems returns N(u) for every user: the movies the user rated along with the user's implicit
+// feedback, without duplicates
+func (p *SVDPP) implicitItems(ratings *Ratings) [][]int {
+	implicit := make([][]int, ratings.NumUsers())
+	for user := range implicit {
+		for _, entry := range ratings.UserRatings(user) {
+			implicit[user] = append(implicit[user], entry.Index)
+		}
+	}
+
+	for _, pair := range p.extra {
+		if pair.User < 0 || pair.User >= len(implicit) || pair.Item < 0 || pair.Item >= ratings.NumItems() || ratings.Has(pair.User, pair.Item) {
+			continue
+		}
+		duplicate := false
+		for _, item := range implicit[pair.User][len(ratings.UserRatings(pair.User)):] {
+			if item == pair.Item {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			implicit[pair.User] = append(implicit[pair.User], pair.Item)
+		}
+	}
+
+	return implicit
+}
+
+// implicitSum sets sum to |N(u)|^-½ · summation( y_j for j in items ) and returns |N(u)|^-½
+func (p *SVDPP) implicitSum(items []int, sum []float64) float64 {
+	for factor := range sum {
+		sum[factor] = 0
+	}
+	if len(items) == 0 {
+		return 0
+	}
+
+	norm := 1 / math.Sqrt(float64(len(items)))
+	for _, item := range items {
+		for factor, value := range p.implicitFactors[item] {
+			sum[factor] += value
+		}
+	}
+	for factor := range sum {
+		sum[factor] *= norm
+	}
+	return norm
+}
+
+// Predict implements Predictor. Users and movies with no ratings are predicted the mean plus whichever
+// bias is known, and the prediction is not OK.
+func (p *SVDPP) Predict(user, item int) Prediction {
+	var userVector []float64
+	if user >= 0 && user < len(p.userVectors) {
+		userVector = p.userVectors[user]
+	}
+	return p.SVD.model.predict(user, item, userVector)
+}
+
+// PredictBatch implements Predictor
+func (p *SVDPP) PredictBatch(pairs []Pair) []Prediction {
+	return predictBatch(p, pairs)
+}
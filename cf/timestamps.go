@@ -0,0 +1,33 @@
+package cf
+
+import "time"
+
+// Timestamps records when ratings were made, for data sets such as MovieLens and the Netflix Prize that
+// include the time of every rating. train.txt has no timestamps.
+type Timestamps struct {
+	times map[pairKey]int64 // Unix time of every rating, in seconds
+}
+
+// NewTimestamps returns an empty set of timestamps
+func NewTimestamps() *Timestamps {
+	return &Timestamps{times: make(map[pairKey]int64)}
+}
+
+// Set records when the user rated the movie, replacing any earlier record
+func (t *Timestamps) Set(user, item int, when time.Time) {
+	t.times[keyOf(user, item)] = when.Unix()
+}
+
+// Get returns when the user rated the movie, and whether that is known
+func (t *Timestamps) Get(user, item int) (time.Time, bool) {
+	unix, ok := t.times[keyOf(user, item)]
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(unix, 0).UTC(), true
+}
+
+// Len returns the number of ratings with a timestamp
+func (t *Timestamps) Len() int {
+	return len(t.times)
+}
@@ -0,0 +1,130 @@
+package cf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Weighting selects how movie ratings are re-weighted before users' similarities are computed
+type Weighting int
+
+const (
+	// NoWeighting compares users on their raw ratings
+	NoWeighting Weighting = iota
+
+	// IUFWeighting multiplies every rating by the movie's inverse user frequency, log(m / mj), where
+	// m is the number of users and mj is the number of users who rated the movie, so that rarely
+	// rated movies say more about how similar two users are.
+	IUFWeighting
+
+	// PolarizationWeighting multiplies every rating by logb(movieSD) - logb(avgSD), so that movies with
+	// polarized ratings (a comparatively large standard deviation) are given more weight. Movies with
+	// fewer than two ratings or no spread in their ratings are left out of the comparison.
+	PolarizationWeighting
+)
+
+var weightingNames = []string{"none", "iuf", "polarization"}
+
+// String returns the weighting's name
+func (w Weighting) String() string {
+	if w < 0 || int(w) >= len(weightingNames) {
+		return fmt.Sprintf("Weighting(%d)", int(w))
+	}
+	return weightingNames[w]
+}
+
+// ParseWeighting returns the weighting with the given name: "none", "iuf" or "polarization"
+func ParseWeighting(name string) (Weighting, error) {
+	for idx, weightingName := range weightingNames {
+		if name == weightingName {
+			return Weighting(idx), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weighting %q (available: %s)", name, strings.Join(weightingNames, ", "))
+}
+
+// weighted returns a copy of the ratings with every rating multiplied by its movie's weight. Ratings of
+// movies with a weight of 0 are left out. NoWeighting returns the ratings themselves.
+func (w Weighting) weighted(ratings *Ratings) (*Ratings, error) {
+	var weights []float64
+
+	switch w {
+	case NoWeighting:
+		return ratings, nil
+	case IUFWeighting:
+		weights = iufWeights(ratings)
+	case PolarizationWeighting:
+		weights = polarizationWeights(ratings)
+	default:
+		return nil, fmt.Errorf("unknown weighting %d", int(w))
+	}
+
+	weighted := NewRatings()
+	weighted.Grow(ratings.NumUsers(), ratings.NumItems())
+	for _, rating := range ratings.All() {
+		if weight := weights[rating.Item]; weight != 0 {
+			weighted.Add(rating.User, rating.Item, rating.Value*weight)
+		}
+	}
+
+	return weighted, nil
+}
+
+// iufWeights returns every movie's inverse user frequency, log(m) - log(mj)
+func iufWeights(ratings *Ratings) []float64 {
+	weights := make([]float64, ratings.NumItems())
+	noOfUsers := float64(ratings.NumUsers()) // Represents m
+
+	for movie := range weights {
+		noOfRatingsForMovie := len(ratings.ItemRatings(movie)) // Represents mj
+
+		if noOfRatingsForMovie > 0 {
+			weights[movie] = math.Log(noOfUsers) - math.Log(float64(noOfRatingsForMovie))
+		}
+	}
+
+	return weights
+}
+
+// polarizationWeights returns logb(movieSD) - logb(avgSD) for every movie, where avgSD is the average
+// standard deviation of all movies' ratings. Movies without a usable standard deviation get a weight of 0.
+func polarizationWeights(ratings *Ratings) []float64 {
+	movieSDs := make([]float64, ratings.NumItems()) // Holds the standard deviations for all movies
+	var sumOfSDs float64 = 0
+	var noOfSDs int
+
+	for movie := range movieSDs {
+		movieRatings := ratings.ItemRatings(movie)
+		noOfRatings := len(movieRatings)
+
+		if noOfRatings < 2 {
+			continue
+		}
+
+		avgRating := mean(movieRatings)
+		var sumForSD float64 = 0
+
+		for _, rating := range movieRatings {
+			sumForSD += math.Pow(rating.Value-avgRating, 2)
+		}
+
+		movieSDs[movie] = math.Sqrt(sumForSD / float64(noOfRatings-1))
+		sumOfSDs += movieSDs[movie]
+		noOfSDs++
+	}
+
+	weights := make([]float64, ratings.NumItems())
+	if noOfSDs == 0 {
+		return weights
+	}
+	avgStandardDeviation := sumOfSDs / float64(noOfSDs)
+
+	for movie, sd := range movieSDs {
+		if sd > 0 {
+			weights[movie] = math.Logb(sd) - math.Logb(avgStandardDeviation)
+		}
+	}
+
+	return weights
+}
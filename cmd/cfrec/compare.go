@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/eval"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/stats"
+)
+
+// runCompare runs several algorithms on the same folds and tests whether their per-user errors differ
+// significantly from those of the first one
+func runCompare(ctx context.Context, args []string) error {
+	set := flag.NewFlagSet("compare", flag.ContinueOnError)
+	model := newModelFlags(set)
+	output := newOutputFlags(set, cf.OutputClamp)
+	splitting := newSplitFlags(set)
+	algos := set.String("algos", "pearson,pearson-case,pearson-iuf,pearson-polar", "comma separated algorithms to compare; the first is the baseline the rest are tested against")
+	metric := set.String("metric", "rmse", "per-user error metric to compare: rmse or mae")
+	resamples := set.Int("resamples", 10000, "number of bootstrap resamples")
+	confidence := set.Float64("confidence", 0.95, "confidence level of the bootstrap intervals")
+	excludeFallbacks := set.Bool("exclude-fallbacks", false, "only score real predictions, leaving out those that fell back on a default value")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+
+	names := strings.Split(*algos, ",")
+	for idx := range names {
+		names[idx] = strings.TrimSpace(names[idx])
+	}
+
+	data, err := model.loadData()
+	if err != nil {
+		return err
+	}
+	splitter, err := splitting.splitter(data.Times)
+	if err != nil {
+		return err
+	}
+	folds, err := splitter.Split(data.Ratings)
+	if err != nil {
+		return err
+	}
+	scale, err := output.ratingScale()
+	if err != nil {
+		return err
+	}
+	policy, err := output.outputPolicy()
+	if err != nil {
+		return err
+	}
+
+	opts := eval.Options{Scale: scale, Output: policy, ExcludeFallbacks: *excludeFallbacks}
+	comparison, err := eval.Compare(ctx, names, model.predictor, folds, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Per-user %s over %d users and %d folds:\n", strings.ToUpper(*metric), len(comparison.Users), len(folds))
+	for idx, name := range names {
+		values, err := comparison.Metric(idx, *metric)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %-14s mean %f\n", name, stats.Mean(values))
+	}
+
+	significanceOpts := eval.SignificanceOptions{Resamples: *resamples, Confidence: *confidence, Seed: splitting.config.Seed}
+	for idx := 1; idx < len(names); idx++ {
+		result, err := comparison.Significance(idx, 0, *metric, significanceOpts)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("\n%s - %s: mean difference %+f, %.0f%% bootstrap interval %v\n",
+			names[idx], names[0], result.TTest.MeanDiff, 100**confidence, result.Bootstrap)
+		fmt.Printf("  paired t-test:        t = %f, df = %d, p = %.4g\n", result.TTest.T, result.TTest.DF, result.TTest.P)
+		fmt.Printf("  Wilcoxon signed-rank: W+ = %g, n = %d, z = %f, p = %.4g\n", result.Wilcoxon.WPlus, result.Wilcoxon.N, result.Wilcoxon.Z, result.Wilcoxon.P)
+	}
+
+	return nil
+}
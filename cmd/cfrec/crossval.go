@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/eval"
+)
+
+// runCrossval reports the mean and standard deviation of one or all algorithms' error metrics across the
+// folds of a random or chronological split of the training set
+func runCrossval(ctx context.Context, args []string) error {
+	set := flag.NewFlagSet("crossval", flag.ContinueOnError)
+	model := newModelFlags(set)
+	output := newOutputFlags(set, cf.OutputClamp)
+	splitting := newSplitFlags(set)
+	excludeFallbacks := set.Bool("exclude-fallbacks", false, "only score real predictions, leaving out those that fell back on a default value")
+	perFold := set.Bool("per-fold", false, "also report the metrics of every fold")
+	set.Usage = func() {
+		fmt.Fprintln(set.Output(), "usage: cfrec crossval [flags]\n\n-algo may also be \"all\" to evaluate every algorithm that predicts ratings.")
+		set.PrintDefaults()
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := model.loadData()
+	if err != nil {
+		return err
+	}
+	splitter, err := splitting.splitter(data.Times)
+	if err != nil {
+		return err
+	}
+	folds, err := splitter.Split(data.Ratings)
+	if err != nil {
+		return err
+	}
+	cold := 0
+	for _, fold := range folds {
+		cold += len(fold.Cold)
+	}
+	if cold > 0 {
+		fmt.Printf("%d ratings by users without training ratings were set aside rather than tested\n", cold)
+	}
+	scale, err := output.ratingScale()
+	if err != nil {
+		return err
+	}
+	policy, err := output.outputPolicy()
+	if err != nil {
+		return err
+	}
+
+	algos := []string{model.algo}
+	if model.algo == "all" {
+		algos = cf.RatingAlgorithms()
+	}
+
+	for _, algo := range algos {
+		newPredictor := func() (cf.Predictor, error) { return model.predictor(algo) }
+		opts := eval.Options{Scale: scale, Output: policy, ExcludeFallbacks: *excludeFallbacks}
+
+		result, err := eval.CrossValidate(ctx, newPredictor, folds, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %v", algo, err)
+		}
+
+		fmt.Printf("%-14s %v\n", algo, result)
+		if *perFold {
+			for idx, report := range result.Folds {
+				fmt.Printf("%-14s fold %d: %v\n", "", idx+1, report)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/eval"
+)
+
+// runEvaluate reports the RMSE, MAE and coverage of one or all algorithms on ratings held out from the later
+// users or movies of the report's train/test splits
+func runEvaluate(ctx context.Context, args []string) error {
+	set := flag.NewFlagSet("evaluate", flag.ContinueOnError)
+	model := newModelFlags(set)
+	output := newOutputFlags(set, cf.OutputClamp)
+	batch := newBatchFlags(set)
+	split := set.String("split", "", "how to split the training set: \"users\" or \"items\" (default items for item-based algorithms, otherwise users)")
+	trainUsers := set.Int("train-users", 175, "number of users whose ratings are all used for training in the users split; the knn algorithms only take neighbours from them")
+	trainItems := set.Int("train-items", 900, "number of movies whose ratings are all used for training in the items split; the knn algorithms only take neighbours from them")
+	holdout := set.Float64("holdout", 0.2, "proportion of the ratings of the other users (or movies) to hold out and test; the rest are used for training")
+	seed := set.Int64("seed", 1, "seed for choosing the held-out ratings")
+	excludeFallbacks := set.Bool("exclude-fallbacks", false, "only score real predictions, leaving out those that fell back on a default value")
+	byRating := set.Bool("by-rating", false, "also break the errors down by actual rating value")
+	timing := set.Bool("time", false, "also report how long fitting and predicting took")
+	set.Usage = func() {
+		fmt.Fprintln(set.Output(), "usage: cfrec evaluate [flags]\n\n-algo may also be \"all\" to evaluate every algorithm that predicts ratings.")
+		set.PrintDefaults()
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *holdout <= 0 || *holdout >= 1 {
+		return fmt.Errorf("-holdout must be between 0 and 1, got %v", *holdout)
+	}
+
+	data, err := model.loadData()
+	if err != nil {
+		return err
+	}
+	ratings := data.Ratings
+
+	algos := []string{model.algo}
+	if model.algo == "all" {
+		algos = cf.RatingAlgorithms()
+	}
+
+	for _, algo := range algos {
+		predictor, err := model.predictor(algo)
+		if err != nil {
+			return err
+		}
+
+		algoSplit := *split
+		if algoSplit == "" {
+			algoSplit = "users"
+			if strings.HasPrefix(algo, "item-") {
+				algoSplit = "items"
+			}
+		}
+
+		// Only the neighbours the report's programs used are candidates, and the held-out ratings are left
+		// out of training so that every error is measured on a rating the predictor has not seen
+		var test []cf.Rating
+		candidates := 0
+		switch algoSplit {
+		case "users":
+			test, candidates = holdOut(cf.TestUsers(ratings, *trainUsers), *holdout, *seed), *trainUsers
+		case "items":
+			test, candidates = holdOut(cf.TestItems(ratings, *trainItems), *holdout, *seed), *trainItems
+		default:
+			return fmt.Errorf("unknown split %q", algoSplit)
+		}
+		if knn, ok := predictor.(*cf.KNN); ok && knn.ItemBased == (algoSplit == "items") {
+			knn.Candidates = candidates
+		}
+
+		start := time.Now()
+		if err := predictor.Fit(ratings.Without(test)); err != nil {
+			return err
+		}
+		fitted := time.Now()
+		predictions, err := batch.predict(ctx, predictor, cf.Pairs(test))
+		if err != nil {
+			return err
+		}
+		predicted := time.Now()
+		if err := output.apply(predictions); err != nil {
+			return err
+		}
+
+		scale, err := output.ratingScale()
+		if err != nil {
+			return err
+		}
+		report, err := eval.Evaluate(test, predictions, eval.Options{Scale: scale, ExcludeFallbacks: *excludeFallbacks})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%-14s %v, %s split\n", algo, report, algoSplit)
+		if *timing {
+			fmt.Printf("%-14s fit: %v, predict: %v\n", "", fitted.Sub(start).Round(time.Microsecond), predicted.Sub(fitted).Round(time.Microsecond))
+		}
+		if *byRating {
+			if err := report.WriteByRating(os.Stdout); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// holdOut returns a random share of the ratings, chosen with the given seed
+func holdOut(ratings []cf.Rating, fraction float64, seed int64) []cf.Rating {
+	random := rand.New(rand.NewSource(seed))
+
+	var held []cf.Rating
+	for _, rating := range ratings {
+		if random.Float64() < fraction {
+			held = append(held, rating)
+		}
+	}
+	return held
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/dataset"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/experiment"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/split"
+)
+
+// modelFlags are the flags shared by every command that fits an algorithm
+type modelFlags struct {
+	set *flag.FlagSet
+
+	algo       string
+	data       string
+	format     string
+	neighbours int
+	hood       string
+	similarity string
+	p          float64
+	params     string
+
+	implicitMin float64
+}
+
+// newModelFlags registers the shared algorithm flags on set
+func newModelFlags(set *flag.FlagSet) *modelFlags {
+	m := &modelFlags{set: set}
+
+	set.StringVar(&m.algo, "algo", "pearson", "algorithm to use, one of: "+strings.Join(cf.Algorithms(), ", "))
+	set.StringVar(&m.data, "data", "train.txt", "rating data set to train on")
+	set.StringVar(&m.format, "format", "", "format of -data, one of: "+strings.Join(dataset.Formats(), ", ")+" (default detected from the file name)")
+	set.IntVar(&m.neighbours, "k", cf.DefaultNeighbours, "number of neighbours each prediction is based on (shorthand for -neighbourhood top-k:<k>)")
+	set.StringVar(&m.hood, "neighbourhood", "", "neighbourhood selection: top-k:<k>, threshold:<min>, top-k-threshold:<k>:<min> or positive (default top-k:20)")
+	set.StringVar(&m.similarity, "similarity", "", "similarity function for the knn algorithms, one of: "+strings.Join(cf.Similarities(), ", ")+" (default depends on -algo)")
+	set.Float64Var(&m.p, "p", 0, "case modification exponent for the pearson algorithms (default 3 for pearson-case, otherwise off)")
+	set.StringVar(&m.params, "params", "", "other algorithm parameters as comma separated name=value pairs")
+	set.Float64Var(&m.implicitMin, "implicit-min", 0, "turn the ratings of at least this value into implicit interactions of strength 1, for implicit-als (default use the ratings as they are)")
+
+	return m
+}
+
+// parameters returns the algorithm parameters given on the command line. Flags left at their defaults are
+// not included, so that each algorithm keeps its own defaults.
+func (m *modelFlags) parameters() (cf.Params, error) {
+	params, err := cf.ParseParams(m.params)
+	if err != nil {
+		return nil, err
+	}
+
+	var visitErr error
+	m.set.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "k":
+			params.Set("neighbours", float64(m.neighbours))
+		case "neighbourhood":
+			hood, err := cf.ParseNeighbourhood(m.hood)
+			if err != nil {
+				visitErr = err
+				return
+			}
+			for name, value := range hood.Params() {
+				params[name] = value
+			}
+		case "similarity":
+			params["similarity"] = m.similarity
+		case "p":
+			params.Set("p", m.p)
+		}
+	})
+
+	return params, visitErr
+}
+
+// predictor returns an unfitted predictor for the named algorithm with the parameters given on the command line
+func (m *modelFlags) predictor(algo string) (cf.Predictor, error) {
+	params, err := m.parameters()
+	if err != nil {
+		return nil, err
+	}
+
+	return cf.New(algo, params)
+}
+
+// loadData reads the training set named by -data, as implicit interactions if -implicit-min is set
+func (m *modelFlags) loadData() (*dataset.Dataset, error) {
+	data, err := dataset.Load(m.data, m.format)
+	if err != nil || m.implicitMin == 0 {
+		return data, err
+	}
+	return data.Implicit(dataset.ImplicitOptions{MinRating: m.implicitMin, Binary: true}), nil
+}
+
+// outputFlags are the flags controlling how predictions are fitted to the rating scale
+type outputFlags struct {
+	policy string
+	scale  string
+}
+
+// newOutputFlags registers the output flags on set, with the given default output policy
+func newOutputFlags(set *flag.FlagSet, defaultPolicy cf.OutputPolicy) *outputFlags {
+	o := &outputFlags{}
+
+	set.StringVar(&o.policy, "output", defaultPolicy.String(), "how predictions are fitted to the rating scale: raw, clamp or round")
+	set.StringVar(&o.scale, "scale", cf.DefaultScale.String(), "rating scale as min:max or min:max:step")
+
+	return o
+}
+
+// ratingScale returns the rating scale given by -scale
+func (o *outputFlags) ratingScale() (cf.Scale, error) {
+	return cf.ParseScale(o.scale)
+}
+
+// outputPolicy returns the output policy given by -output
+func (o *outputFlags) outputPolicy() (cf.OutputPolicy, error) {
+	return cf.ParseOutputPolicy(o.policy)
+}
+
+// apply fits the predictions to the rating scale in place
+func (o *outputFlags) apply(predictions []cf.Prediction) error {
+	policy, err := o.outputPolicy()
+	if err != nil {
+		return err
+	}
+	scale, err := o.ratingScale()
+	if err != nil {
+		return err
+	}
+
+	cf.ApplyOutput(predictions, policy, scale)
+	return nil
+}
+
+// batchFlags are the flags controlling how batches of predictions are spread over goroutines
+type batchFlags struct {
+	workers  int
+	progress bool
+}
+
+// newBatchFlags registers the batch prediction flags on set
+func newBatchFlags(set *flag.FlagSet) *batchFlags {
+	b := &batchFlags{}
+
+	set.IntVar(&b.workers, "workers", 0, "number of goroutines making predictions (default one per CPU)")
+	set.BoolVar(&b.progress, "progress", false, "report prediction progress on standard error")
+
+	return b
+}
+
+// predict makes a prediction for every pair with the fitted predictor, stopping early if ctx is cancelled
+func (b *batchFlags) predict(ctx context.Context, predictor cf.Predictor, pairs []cf.Pair) ([]cf.Prediction, error) {
+	opts := cf.BatchOptions{Workers: b.workers}
+	if b.progress {
+		lastPercent := -1
+		opts.Progress = func(done, total int) {
+			if percent := done * 100 / total; percent != lastPercent {
+				lastPercent = percent
+				fmt.Fprintf(os.Stderr, "\rpredicted %d of %d (%d%%)", done, total, percent)
+			}
+			if done == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		}
+	}
+
+	return cf.PredictParallel(ctx, predictor, pairs, opts)
+}
+
+// splitFlags are the flags choosing how the training set is split into folds
+type splitFlags struct {
+	config experiment.SplitConfig
+}
+
+// newSplitFlags registers the split flags on set
+func newSplitFlags(set *flag.FlagSet) *splitFlags {
+	s := &splitFlags{}
+	c := &s.config
+
+	set.StringVar(&c.Method, "split", "kfold", "how to split the ratings into folds, one of: "+strings.Join(experiment.SplitMethods(), ", ")+
+		"; cutoff, last-n and sliding need a data set with timestamps")
+	set.IntVar(&c.Folds, "folds", 5, "number of folds for kfold and user-kfold")
+	set.IntVar(&c.N, "n", 1, "number of ratings per user to hold out for leave-n-out and last-n, or to keep for given-n")
+	set.Float64Var(&c.Fraction, "fraction", 0.2, "proportion of the ratings to hold out for holdout, or of each test user's ratings for user-kfold")
+	set.IntVar(&c.FirstTestUser, "train-users", 175, "number of users left out of the test set for given-n")
+	set.Int64Var(&c.Seed, "seed", 1, "seed for the random splits")
+	set.StringVar(&c.Cutoff, "cutoff", "", "date (YYYY-MM-DD) or Unix time from which ratings are tested, for cutoff")
+	set.StringVar(&c.Start, "start", "", "date (YYYY-MM-DD) or Unix time the first test window starts at, for sliding")
+	set.IntVar(&c.WindowDays, "window-days", 30, "length of each test window in days, for sliding")
+	set.IntVar(&c.Windows, "windows", 5, "number of test windows, for sliding")
+	set.IntVar(&c.HistoryDays, "history-days", 0, "only train on the ratings made this many days before each window, for sliding (default all of them)")
+
+	return s
+}
+
+// splitter returns the splitter chosen on the command line. times may be nil for data sets without timestamps.
+func (s *splitFlags) splitter(times *cf.Timestamps) (split.Splitter, error) {
+	return s.config.Splitter(times)
+}
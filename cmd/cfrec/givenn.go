@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/dataset"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/eval"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/split"
+)
+
+// runMask builds a given-N test file in the format of test5.txt from the training set, along with an answer
+// key holding the ratings it hides
+func runMask(ctx context.Context, args []string) error {
+	set := flag.NewFlagSet("mask", flag.ContinueOnError)
+	data := set.String("data", "train.txt", "rating data set to build the test set from")
+	format := set.String("format", "", "format of -data, one of: "+strings.Join(dataset.Formats(), ", ")+" (default detected from the file name)")
+	n := set.Int("n", 5, "number of ratings each test user keeps")
+	trainUsers := set.Int("train-users", 175, "number of users left out of the test set; the rest are tested")
+	seed := set.Int64("seed", 1, "seed for choosing the ratings each test user keeps")
+	testPath := set.String("test", "", "file to write the test set to (required)")
+	keyPath := set.String("key", "", "file to write the answer key to (required)")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *testPath == "" || *keyPath == "" {
+		return errors.New("-test and -key are required")
+	}
+
+	loaded, err := dataset.Load(*data, *format)
+	if err != nil {
+		return err
+	}
+
+	folds, err := split.GivenN{N: *n, FirstTestUser: *trainUsers, Seed: *seed}.Split(loaded.Ratings)
+	if err != nil {
+		return err
+	}
+	fold := folds[0]
+
+	// Like test5.txt, list each test user's given ratings followed by the movies to predict, marked with 0
+	var test []cf.Rating
+	for start := 0; start < len(fold.Test); {
+		user := fold.Test[start].User
+		end := start
+		for end < len(fold.Test) && fold.Test[end].User == user {
+			end++
+		}
+
+		for _, given := range fold.Train.UserRatings(user) {
+			test = append(test, cf.Rating{User: user, Item: given.Index, Value: given.Value})
+		}
+		for _, hidden := range fold.Test[start:end] {
+			test = append(test, cf.Rating{User: user, Item: hidden.Item})
+		}
+		start = end
+	}
+
+	if err := writeTriples(*testPath, loaded, test); err != nil {
+		return err
+	}
+	if err := writeTriples(*keyPath, loaded, fold.Test); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote given %d test set with %d ratings to predict to %s, and the answers to %s\n", *n, len(fold.Test), *testPath, *keyPath)
+	return nil
+}
+
+// writeTriples writes the ratings to the named file as "user movie rating" triples
+func writeTriples(path string, data *dataset.Dataset, ratings []cf.Rating) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := data.WriteTriples(file, ratings); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// runScore scores one or all algorithms on a test file such as test5.txt against its answer key. The test
+// users' own ratings in the training set, if any, are replaced by the ones the test file gives. All the
+// algorithms are ranked over the same ratings: those every one of them scored.
+func runScore(ctx context.Context, args []string) error {
+	set := flag.NewFlagSet("score", flag.ContinueOnError)
+	model := newModelFlags(set)
+	output := newOutputFlags(set, cf.OutputClamp)
+	batch := newBatchFlags(set)
+	testPath := set.String("test", "", "file of \"user movie rating\" triples with the ratings to predict marked 0 (required)")
+	keyPath := set.String("key", "", "file of \"user movie rating\" triples holding the actual ratings (required)")
+	excludeFallbacks := set.Bool("exclude-fallbacks", false, "only score real predictions, leaving out those that fell back on a default value")
+	byRating := set.Bool("by-rating", false, "also break the errors down by actual rating value")
+	set.Usage = func() {
+		fmt.Fprintln(set.Output(), "usage: cfrec score [flags]\n\n-algo may also be \"all\" to score every algorithm that predicts ratings, ranked by RMSE over\nthe test ratings every one of them scored; coverage is over all of the test ratings.")
+		set.PrintDefaults()
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *testPath == "" || *keyPath == "" {
+		return errors.New("-test and -key are required")
+	}
+
+	data, err := model.loadData()
+	if err != nil {
+		return err
+	}
+	triples, err := dataset.LoadTriples(*testPath)
+	if err != nil {
+		return fmt.Errorf("reading %s: %v", *testPath, err)
+	}
+	keyTriples, err := dataset.LoadTriples(*keyPath)
+	if err != nil {
+		return fmt.Errorf("reading %s: %v", *keyPath, err)
+	}
+
+	// Test users are only known through the ratings the test file gives
+	var known []cf.Rating
+	removed := map[int]bool{}
+	for _, triple := range triples {
+		if user, ok := data.Users.Lookup(triple.User); ok && !removed[user] {
+			removed[user] = true
+			for _, entry := range data.Ratings.UserRatings(user) {
+				known = append(known, cf.Rating{User: user, Item: entry.Index, Value: entry.Value})
+			}
+		}
+	}
+	data.Ratings = data.Ratings.Without(known)
+
+	queryPairs := data.AddTriples(triples)
+	queries := map[cf.Pair]bool{}
+	for _, pair := range queryPairs {
+		queries[pair] = true
+	}
+	actual, err := data.Resolve(keyTriples)
+	if err != nil {
+		return fmt.Errorf("%s: %v", *keyPath, err)
+	}
+	for _, rating := range actual {
+		if !queries[cf.Pair{User: rating.User, Item: rating.Item}] {
+			return fmt.Errorf("%s has an answer for user %s and movie %s, which %s does not ask for",
+				*keyPath, data.Users.ID(rating.User), data.Items.ID(rating.Item), *testPath)
+		}
+	}
+
+	scale, err := output.ratingScale()
+	if err != nil {
+		return err
+	}
+
+	algos := []string{model.algo}
+	if model.algo == "all" {
+		algos = cf.RatingAlgorithms()
+	}
+
+	predicted := make([][]cf.Prediction, len(algos))
+	for idx, algo := range algos {
+		predictor, err := model.predictor(algo)
+		if err != nil {
+			return err
+		}
+		addImplicit(predictor, queryPairs)
+		if err := predictor.Fit(data.Ratings); err != nil {
+			return err
+		}
+
+		predictions, err := batch.predict(ctx, predictor, cf.Pairs(actual))
+		if err != nil {
+			return err
+		}
+		if err := output.apply(predictions); err != nil {
+			return err
+		}
+		predicted[idx] = predictions
+	}
+
+	// With several algorithms, rank them by RMSE over only the ratings every one of them scored
+	opts := eval.Options{Scale: scale, ExcludeFallbacks: *excludeFallbacks}
+	reports, common, err := eval.EvaluateCommon(actual, predicted, opts)
+	if err != nil {
+		return err
+	}
+	order := make([]int, len(algos))
+	for idx := range order {
+		order[idx] = idx
+	}
+	if len(algos) > 1 {
+		sort.SliceStable(order, func(i, j int) bool {
+			a, b := reports[order[i]].RMSE, reports[order[j]].RMSE
+			return a < b || (math.IsNaN(b) && !math.IsNaN(a))
+		})
+		fmt.Printf("ranked by RMSE over the %d of %d test ratings every algorithm scored:\n", common, len(actual))
+	}
+
+	for _, idx := range order {
+		fmt.Printf("%-18s %v\n", algos[idx], reports[idx])
+		if *byRating {
+			if err := reports[idx].WriteByRating(os.Stdout); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+// Command cfrec trains, evaluates and makes predictions and recommendations with the collaborative
+// filtering algorithms in package cf.
+//
+// Usage:
+//
+//	cfrec <command> [flags]
+//
+// The commands are:
+//
+//	train      fit an algorithm on a training set and report how long it took
+//	evaluate   measure an algorithm's RMSE on a train/test split of the training set
+//	predict    predict the missing ratings in a test file such as test5.txt
+//	recommend  list the movies with the highest predicted ratings for a user
+//	crossval   measure an algorithm's error metrics across the folds of a split
+//	mask       build a given-N test file like test5.txt and its answer key from the training set
+//	score      measure algorithms' error metrics on a test file against its answer key
+//	compare    test whether algorithms' per-user errors differ significantly on the same folds
+//	rank       measure how well an algorithm ranks held-out movies with top-N metrics
+//	run        carry out the experiment in a config file and write a manifest of the run
+//	search     tune the algorithms in a config file by grid or random search and write the best config
+//
+// Run "cfrec <command> -h" for the flags each command accepts.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+// commands maps every subcommand's name to the function that runs it
+var commands = map[string]func(ctx context.Context, args []string) error{
+	"train":     runTrain,
+	"evaluate":  runEvaluate,
+	"predict":   runPredict,
+	"recommend": runRecommend,
+	"rank":      runRank,
+	"crossval":  runCrossval,
+	"mask":      runMask,
+	"score":     runScore,
+	"compare":   runCompare,
+	"run":       runExperiment,
+	"search":    runSearch,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "cfrec: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	// Interrupting cfrec cancels any batch of predictions in progress
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := run(ctx, os.Args[2:])
+	stop()
+
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "cfrec %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cfrec <command> [flags]")
+	fmt.Fprintln(os.Stderr, "commands: train, evaluate, predict, recommend, rank, crossval, mask, score, compare, run, search")
+	fmt.Fprintln(os.Stderr, "run \"cfrec <command> -h\" for the flags each command accepts")
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/dataset"
+)
+
+// runPredict predicts every rating of 0 in a test file, using the test file's other ratings along with the
+// training set, and writes the predictions in the format of result5.txt
+func runPredict(ctx context.Context, args []string) error {
+	set := flag.NewFlagSet("predict", flag.ContinueOnError)
+	model := newModelFlags(set)
+	output := newOutputFlags(set, cf.OutputRound)
+	batch := newBatchFlags(set)
+	testPath := set.String("test", "", "file of \"user movie rating\" triples to predict the 0 ratings of (required)")
+	outPath := set.String("out", "", "file to write the predictions to (default standard output)")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *testPath == "" {
+		return errors.New("-test is required")
+	}
+
+	data, pairs, err := loadWithTest(model, *testPath)
+	if err != nil {
+		return err
+	}
+
+	predictor, err := model.predictor(model.algo)
+	if err != nil {
+		return err
+	}
+	addImplicit(predictor, pairs)
+	if err := predictor.Fit(data.Ratings); err != nil {
+		return err
+	}
+	predictions, err := batch.predict(ctx, predictor, pairs)
+	if err != nil {
+		return err
+	}
+	if err := output.apply(predictions); err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		file, err := os.Create(*outPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := data.WritePredictions(out, predictions); err != nil {
+		return err
+	}
+
+	if *outPath != "" {
+		fmt.Fprintf(os.Stderr, "Wrote %d predictions to %s\n", len(predictions), *outPath)
+	}
+
+	return nil
+}
+
+// loadWithTest reads the training set and adds the known (non-zero) ratings from a test file to it. It also
+// returns the pairs whose ratings the test file asks to be predicted.
+func loadWithTest(model *modelFlags, testPath string) (*dataset.Dataset, []cf.Pair, error) {
+	data, err := model.loadData()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	triples, err := dataset.LoadTriples(testPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading %s: %v", testPath, err)
+	}
+
+	return data, data.AddTriples(triples), nil
+}
+
+// addImplicit tells predictors that learn from implicit feedback which movies a test file asks about, since
+// the test file shows the users rated them
+func addImplicit(p cf.Predictor, pairs []cf.Pair) {
+	if implicit, ok := p.(cf.ImplicitPredictor); ok {
+		implicit.AddImplicit(pairs)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/eval"
+)
+
+// runRank reports how well an algorithm ranks held-out movies for the test users, using the top-N metrics
+func runRank(ctx context.Context, args []string) error {
+	set := flag.NewFlagSet("rank", flag.ContinueOnError)
+	model := newModelFlags(set)
+	k := set.Int("k-list", eval.DefaultK, "length of the recommendation lists precision, recall, NDCG and hit rate look at")
+	threshold := set.Float64("threshold", eval.DefaultRelevanceThreshold, "lowest held-out rating that makes a movie relevant")
+	trainUsers := set.Int("train-users", 175, "number of users used only for training; some ratings of the rest are held out")
+	holdout := set.Float64("holdout", 0.2, "proportion of each test user's ratings to hold out")
+	seed := set.Int64("seed", 1, "seed for choosing the held-out ratings")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *holdout <= 0 || *holdout >= 1 {
+		return fmt.Errorf("-holdout must be between 0 and 1, got %v", *holdout)
+	}
+
+	data, err := model.loadData()
+	if err != nil {
+		return err
+	}
+
+	// Hold out a random share of every test user's ratings; the rest stay in the training set
+	test := holdOut(cf.TestUsers(data.Ratings, *trainUsers), *holdout, *seed)
+	train := data.Ratings.Without(test)
+
+	algos := []string{model.algo}
+	if model.algo == "all" {
+		algos = cf.Algorithms()
+	}
+
+	for _, algo := range algos {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		predictor, err := model.predictor(algo)
+		if err != nil {
+			return err
+		}
+		if err := predictor.Fit(train); err != nil {
+			return err
+		}
+
+		report, err := eval.EvaluateRanking(predictor, train, test, eval.RankingOptions{K: *k, Threshold: *threshold})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%-14s %v\n", algo, report)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/dataset"
+)
+
+// runRecommend lists the movies with the highest predicted ratings that a user has not rated yet
+func runRecommend(ctx context.Context, args []string) error {
+	set := flag.NewFlagSet("recommend", flag.ContinueOnError)
+	model := newModelFlags(set)
+	output := newOutputFlags(set, cf.OutputClamp)
+	userID := set.String("user", "1", "ID of the user to recommend movies to")
+	n := set.Int("n", 10, "number of movies to recommend")
+	testPath := set.String("test", "", "optional test file whose known ratings are added to the training set, for its users")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+
+	var data *dataset.Dataset
+	var err error
+	if *testPath != "" {
+		data, _, err = loadWithTest(model, *testPath)
+	} else {
+		data, err = model.loadData()
+	}
+	if err != nil {
+		return err
+	}
+
+	user, ok := data.Users.Lookup(*userID)
+	if !ok || len(data.Ratings.UserRatings(user)) == 0 {
+		return fmt.Errorf("user %s has no ratings", *userID)
+	}
+
+	predictor, err := model.predictor(model.algo)
+	if err != nil {
+		return err
+	}
+	if err := predictor.Fit(data.Ratings); err != nil {
+		return err
+	}
+
+	recommendations := cf.Recommend(predictor, data.Ratings, user, *n)
+	if err := output.apply(recommendations); err != nil {
+		return err
+	}
+
+	for rank, recommendation := range recommendations {
+		fmt.Printf("%2d. movie %s (predicted rating %.2f)\n", rank+1, data.Items.ID(recommendation.Item), recommendation.Value)
+	}
+
+	return nil
+}
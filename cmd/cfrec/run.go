@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/experiment"
+)
+
+// runExperiment carries out the experiment described in a config file and writes its manifest
+func runExperiment(ctx context.Context, args []string) error {
+	set := flag.NewFlagSet("run", flag.ContinueOnError)
+	configPath := set.String("config", "", "path of the JSON experiment config (required)")
+	manifestPath := set.String("manifest", "", "path to write the run manifest to (default the config path with .manifest.json in place of .json)")
+	set.Usage = func() {
+		fmt.Fprintln(set.Output(), "usage: cfrec run -config experiment.json [flags]")
+		set.PrintDefaults()
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *configPath == "" {
+		return fmt.Errorf("-config is required")
+	}
+	if *manifestPath == "" {
+		*manifestPath = strings.TrimSuffix(*configPath, ".json") + ".manifest.json"
+	}
+
+	config, err := experiment.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := experiment.Run(ctx, config, func(result experiment.Result) {
+		fmt.Printf("%-14s RMSE %f ± %f  MAE %f ± %f  coverage %f\n", result.Algorithm,
+			result.RMSE.Mean, result.RMSE.Std, result.MAE.Mean, result.MAE.Std, result.Coverage.Mean)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := manifest.WriteFile(*manifestPath); err != nil {
+		return err
+	}
+	fmt.Printf("manifest written to %s\n", *manifestPath)
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/experiment"
+)
+
+// runSearch tunes the algorithms in an experiment config by grid or random search, prints the leaderboard
+// and writes the best configuration found as a new config file
+func runSearch(ctx context.Context, args []string) error {
+	set := flag.NewFlagSet("search", flag.ContinueOnError)
+	configPath := set.String("config", "", "path of the JSON experiment config giving the data set, split and algorithms to tune (required)")
+	method := set.String("method", "grid", "search method: grid tries every combination, random samples -trials of them per algorithm")
+	trials := set.Int("trials", 20, "number of combinations per algorithm for random search")
+	seed := set.Int64("seed", 1, "seed for random search")
+	metric := set.String("metric", "rmse", "metric to rank the combinations by: rmse, mae or nmae")
+	minCoverage := set.Float64("min-coverage", 0, "rank combinations whose coverage is below this proportion after the rest")
+	top := set.Int("top", 10, "number of leaderboard entries to print; 0 prints all of them")
+	bestPath := set.String("best", "", "path to write the best configuration to (default the config path with .best.json in place of .json)")
+	manifestPath := set.String("manifest", "", "path to write the search manifest to (default the config path with .search.json in place of .json)")
+	set.Usage = func() {
+		fmt.Fprintln(set.Output(), "usage: cfrec search -config experiment.json [flags]\n\nParameters the config sets for an algorithm are held fixed; the rest of its declared space is searched.")
+		set.PrintDefaults()
+	}
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if *configPath == "" {
+		return fmt.Errorf("-config is required")
+	}
+	base := strings.TrimSuffix(*configPath, ".json")
+	if *bestPath == "" {
+		*bestPath = base + ".best.json"
+	}
+	if *manifestPath == "" {
+		*manifestPath = base + ".search.json"
+	}
+
+	config, err := experiment.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	opts := experiment.SearchOptions{Method: *method, Trials: *trials, Seed: *seed, Metric: *metric, MinCoverage: *minCoverage}
+	manifest, err := experiment.Search(ctx, config, opts, func(result experiment.Result) {
+		fmt.Printf("%-14s %-40s RMSE %f  MAE %f  coverage %.1f%%\n", result.Algorithm, result.Params.Format(),
+			result.RMSE.Mean, result.MAE.Mean, 100*result.Coverage.Mean)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nleaderboard by %s:\n", *metric)
+	for idx, result := range manifest.Results {
+		if *top > 0 && idx == *top {
+			break
+		}
+		fmt.Printf("%3d. %-14s %-40s RMSE %f ± %f  MAE %f ± %f  coverage %.1f%%\n", idx+1, result.Algorithm, result.Params.Format(),
+			result.RMSE.Mean, result.RMSE.Std, result.MAE.Mean, result.MAE.Std, 100*result.Coverage.Mean)
+	}
+
+	best, err := manifest.BestConfig()
+	if err != nil {
+		return err
+	}
+	if err := best.WriteFile(*bestPath); err != nil {
+		return err
+	}
+	if err := manifest.WriteFile(*manifestPath); err != nil {
+		return err
+	}
+	fmt.Printf("\nbest configuration written to %s and manifest to %s\n", *bestPath, *manifestPath)
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// runTrain fits an algorithm on the training set and reports the size of the data and how long fitting took
+func runTrain(ctx context.Context, args []string) error {
+	set := flag.NewFlagSet("train", flag.ContinueOnError)
+	model := newModelFlags(set)
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := model.loadData()
+	if err != nil {
+		return err
+	}
+
+	predictor, err := model.predictor(model.algo)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := predictor.Fit(data.Ratings); err != nil {
+		return err
+	}
+
+	fmt.Printf("Fitted %s on %d ratings from %d users of %d movies in %v\n",
+		model.algo, data.Ratings.Len(), data.Ratings.NumUsers(), data.Ratings.NumItems(), time.Since(start).Round(time.Microsecond))
+
+	return nil
+}
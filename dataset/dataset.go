@@ -0,0 +1,187 @@
+// Package dataset loads rating data sets into the rating store used by package cf. Besides the course's
+// dense train.txt matrix it reads the public MovieLens and Netflix Prize formats, mapping their external
+// user and movie IDs to the dense indexes cf works with.
+package dataset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+)
+
+// Dataset is a rating store along with the external IDs of its users and movies
+type Dataset struct {
+	Ratings *cf.Ratings
+	Users   *IDMap         // Maps external user IDs to user indexes in Ratings
+	Items   *IDMap         // Maps external movie IDs to movie indexes in Ratings
+	Times   *cf.Timestamps // When each rating was made; nil for formats without timestamps, such as train.txt
+}
+
+// New returns an empty data set
+func New() *Dataset {
+	return &Dataset{Ratings: cf.NewRatings(), Users: NewIDMap(), Items: NewIDMap()}
+}
+
+// Add stores the rating the user gave the movie, giving new users and movies the next free index
+func (d *Dataset) Add(user, item string, value float64) {
+	d.Ratings.Add(d.Users.Index(user), d.Items.Index(item), value)
+}
+
+// AddAt stores the rating the user gave the movie along with when it was made
+func (d *Dataset) AddAt(user, item string, value float64, when time.Time) {
+	if d.Times == nil {
+		d.Times = cf.NewTimestamps()
+	}
+
+	userIndex, itemIndex := d.Users.Index(user), d.Items.Index(item)
+	d.Ratings.Add(userIndex, itemIndex, value)
+	d.Times.Set(userIndex, itemIndex, when)
+}
+
+// IDMap assigns dense indexes, starting at 0, to external IDs in the order they are first seen
+type IDMap struct {
+	ids   []string
+	index map[string]int
+}
+
+// NewIDMap returns an empty ID map
+func NewIDMap() *IDMap {
+	return &IDMap{index: make(map[string]int)}
+}
+
+// sequentialIDMap returns an ID map where the IDs "1" to "n" map to the indexes 0 to n-1
+func sequentialIDMap(n int) *IDMap {
+	m := NewIDMap()
+	for idx := 0; idx < n; idx++ {
+		m.Index(strconv.Itoa(idx + 1))
+	}
+	return m
+}
+
+// Index returns the index of the ID, assigning it the next free index if it has not been seen before
+func (m *IDMap) Index(id string) int {
+	if idx, ok := m.index[id]; ok {
+		return idx
+	}
+
+	idx := len(m.ids)
+	m.ids = append(m.ids, id)
+	m.index[id] = idx
+	return idx
+}
+
+// Lookup returns the index of the ID, and whether it has been seen before
+func (m *IDMap) Lookup(id string) (int, bool) {
+	idx, ok := m.index[id]
+	return idx, ok
+}
+
+// ID returns the external ID of the index
+func (m *IDMap) ID(index int) string {
+	return m.ids[index]
+}
+
+// Len returns the number of IDs in the map
+func (m *IDMap) Len() int {
+	return len(m.ids)
+}
+
+// Formats that Load understands
+const (
+	FormatDense   = "dense"   // The course's train.txt: one line of whitespace separated ratings per user, 0 for unrated
+	FormatUData   = "udata"   // MovieLens 100K u.data: user, movie, rating and timestamp separated by tabs
+	FormatDat     = "dat"     // MovieLens 1M and 10M ratings.dat: user::movie::rating::timestamp
+	FormatCSV     = "csv"     // MovieLens 20M, 25M and latest ratings.csv: userId,movieId,rating,timestamp with a header
+	FormatNetflix = "netflix" // Netflix Prize training_set directory of mv_*.txt files, or a single such file
+	FormatEvents  = "events"  // Interaction log of "user movie [count]" lines, such as views or clicks; see ReadEvents
+)
+
+// Formats returns the name of every format Load understands
+func Formats() []string {
+	return []string{FormatDense, FormatUData, FormatDat, FormatCSV, FormatNetflix, FormatEvents}
+}
+
+// Load reads the named file or directory in the given format. An empty format is detected from the path
+// with DetectFormat.
+func Load(path, format string) (*Dataset, error) {
+	if format == "" {
+		var err error
+		if format, err = DetectFormat(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var d *Dataset
+	var err error
+
+	switch format {
+	case FormatDense:
+		d, err = LoadDense(path)
+	case FormatUData:
+		d, err = LoadUData(path)
+	case FormatDat:
+		d, err = LoadDat(path)
+	case FormatCSV:
+		d, err = LoadCSV(path)
+	case FormatNetflix:
+		d, err = LoadNetflix(path)
+	case FormatEvents:
+		d, err = LoadEvents(path)
+	default:
+		return nil, fmt.Errorf("unknown format %q (available: %s)", format, strings.Join(Formats(), ", "))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %v", path, err)
+	}
+	return d, nil
+}
+
+// DetectFormat guesses the format of a data set from its path: directories and mv_*.txt files are Netflix
+// Prize data, *.data is MovieLens 100K, *.dat is MovieLens 1M/10M, *.csv is MovieLens 20M and later,
+// *.events is an interaction log, and any other file is a dense matrix like train.txt
+func DetectFormat(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return FormatNetflix, nil
+	}
+
+	base := filepath.Base(path)
+	switch {
+	case strings.HasPrefix(base, "mv_"):
+		return FormatNetflix, nil
+	case strings.HasSuffix(base, ".data"):
+		return FormatUData, nil
+	case strings.HasSuffix(base, ".dat"):
+		return FormatDat, nil
+	case strings.HasSuffix(base, ".csv"):
+		return FormatCSV, nil
+	case strings.HasSuffix(base, ".events"):
+		return FormatEvents, nil
+	default:
+		return FormatDense, nil
+	}
+}
+
+// LoadDense reads a dense rating matrix such as train.txt. Users and movies get the IDs "1", "2", ... in the
+// order of the matrix's rows and columns, matching the IDs used in the test files.
+func LoadDense(path string) (*Dataset, error) {
+	ratings, err := cf.LoadDense(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Dataset{
+		Ratings: ratings,
+		Users:   sequentialIDMap(ratings.NumUsers()),
+		Items:   sequentialIDMap(ratings.NumItems()),
+	}, nil
+}
@@ -0,0 +1,87 @@
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+)
+
+// ImplicitOptions controls how ratings are turned into implicit interactions, for algorithms such as
+// implicit ALS that learn from what users watched rather than how they rated it
+type ImplicitOptions struct {
+	// MinRating drops the ratings below it, treating them as movies the user did not like enough to count;
+	// 0 keeps every rating
+	MinRating float64 `json:"min_rating,omitempty"`
+
+	// Binary gives every kept rating a strength of 1; otherwise the rating itself is its strength
+	Binary bool `json:"binary,omitempty"`
+}
+
+// Implicit returns a copy of the data set with its ratings turned into implicit interactions. The copy
+// shares the data set's IDs and timestamps, so users and movies keep their indexes.
+func (d *Dataset) Implicit(opts ImplicitOptions) *Dataset {
+	implicit := &Dataset{Ratings: cf.NewRatings(), Users: d.Users, Items: d.Items, Times: d.Times}
+	implicit.Ratings.Grow(d.Ratings.NumUsers(), d.Ratings.NumItems())
+
+	for _, rating := range d.Ratings.All() {
+		if rating.Value < opts.MinRating {
+			continue
+		}
+		if opts.Binary {
+			rating.Value = 1
+		}
+		implicit.Ratings.Add(rating.User, rating.Item, rating.Value)
+	}
+
+	return implicit
+}
+
+// LoadEvents reads an interaction log, such as a log of views or clicks
+func LoadEvents(path string) (*Dataset, error) {
+	return loadFile(path, ReadEvents)
+}
+
+// ReadEvents reads "user movie [count]" lines separated by whitespace or commas. Every line is one or count
+// interactions, and the interactions of a user with a movie are added up, so a raw log with a line per view
+// gives the number of views.
+func ReadEvents(reader io.Reader) (*Dataset, error) {
+	d := New()
+	separator := func(r rune) bool { return r == ',' || unicode.IsSpace(r) }
+
+	scanner := bufio.NewScanner(reader)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		fields := strings.FieldsFunc(scanner.Text(), separator)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 && len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected user, movie and an optional count, got %q", lineNo, scanner.Text())
+		}
+
+		count := 1.0
+		if len(fields) == 3 {
+			var err error
+			if count, err = strconv.ParseFloat(fields[2], 64); err != nil || count < 0 {
+				return nil, fmt.Errorf("line %d: invalid count %q", lineNo, fields[2])
+			}
+		}
+
+		user, item := d.Users.Index(fields[0]), d.Items.Index(fields[1])
+		previous, _ := d.Ratings.Get(user, item)
+		d.Ratings.Add(user, item, previous+count)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if d.Ratings.Len() == 0 {
+		return nil, fmt.Errorf("no interactions found")
+	}
+
+	return d, nil
+}
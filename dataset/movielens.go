@@ -0,0 +1,94 @@
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadUData reads a MovieLens 100K u.data file
+func LoadUData(path string) (*Dataset, error) {
+	return loadFile(path, ReadUData)
+}
+
+// ReadUData reads MovieLens 100K "user movie rating timestamp" lines separated by tabs
+func ReadUData(reader io.Reader) (*Dataset, error) {
+	return readDelimited(reader, "\t", false)
+}
+
+// LoadDat reads a MovieLens 1M or 10M ratings.dat file
+func LoadDat(path string) (*Dataset, error) {
+	return loadFile(path, ReadDat)
+}
+
+// ReadDat reads MovieLens 1M and 10M "user::movie::rating::timestamp" lines
+func ReadDat(reader io.Reader) (*Dataset, error) {
+	return readDelimited(reader, "::", false)
+}
+
+// LoadCSV reads a MovieLens 20M, 25M or latest ratings.csv file
+func LoadCSV(path string) (*Dataset, error) {
+	return loadFile(path, ReadCSV)
+}
+
+// ReadCSV reads MovieLens "userId,movieId,rating,timestamp" lines following a header line
+func ReadCSV(reader io.Reader) (*Dataset, error) {
+	return readDelimited(reader, ",", true)
+}
+
+func loadFile(path string, read func(io.Reader) (*Dataset, error)) (*Dataset, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return read(file)
+}
+
+// readDelimited reads "user movie rating [timestamp]" lines whose fields are separated by sep, skipping the
+// first line if it is a header
+func readDelimited(reader io.Reader, sep string, header bool) (*Dataset, error) {
+	d := New()
+
+	scanner := bufio.NewScanner(reader)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || (header && lineNo == 1) {
+			continue
+		}
+
+		fields := strings.Split(line, sep)
+		if len(fields) != 3 && len(fields) != 4 {
+			return nil, fmt.Errorf("line %d: expected user%[2]smovie%[2]srating%[2]stimestamp, got %[3]q", lineNo, sep, line)
+		}
+
+		value, err := strconv.ParseFloat(strings.TrimSpace(fields[2]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rating %q", lineNo, fields[2])
+		}
+		user, item := strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1])
+		if len(fields) == 4 {
+			timestamp, err := strconv.ParseInt(strings.TrimSpace(fields[3]), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid timestamp %q", lineNo, fields[3])
+			}
+			d.AddAt(user, item, value, time.Unix(timestamp, 0))
+		} else {
+			d.Add(user, item, value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if d.Ratings.Len() == 0 {
+		return nil, fmt.Errorf("no ratings found")
+	}
+
+	return d, nil
+}
@@ -0,0 +1,106 @@
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadNetflix reads Netflix Prize ratings from either the training_set directory, in which case every
+// mv_*.txt file in it is read, or from a single file
+func LoadNetflix(path string) (*Dataset, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{path}
+	if info.IsDir() {
+		if paths, err = filepath.Glob(filepath.Join(path, "mv_*.txt")); err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no mv_*.txt files found")
+		}
+		sort.Strings(paths)
+	}
+
+	d := New()
+	for _, moviePath := range paths {
+		file, err := os.Open(moviePath)
+		if err != nil {
+			return nil, err
+		}
+
+		err = readNetflix(file, d)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filepath.Base(moviePath), err)
+		}
+	}
+
+	if d.Ratings.Len() == 0 {
+		return nil, fmt.Errorf("no ratings found")
+	}
+
+	return d, nil
+}
+
+// ReadNetflix reads ratings in the Netflix Prize layout: a "movie:" line followed by one
+// "customer,rating,date" line per rating of that movie. Several movies may follow each other, as they do
+// in the combined_data_*.txt files some mirrors distribute.
+func ReadNetflix(reader io.Reader) (*Dataset, error) {
+	d := New()
+	if err := readNetflix(reader, d); err != nil {
+		return nil, err
+	}
+	if d.Ratings.Len() == 0 {
+		return nil, fmt.Errorf("no ratings found")
+	}
+
+	return d, nil
+}
+
+func readNetflix(reader io.Reader, d *Dataset) error {
+	movie := ""
+
+	scanner := bufio.NewScanner(reader)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasSuffix(line, ":") {
+			movie = strings.TrimSuffix(line, ":")
+			continue
+		}
+		if movie == "" {
+			return fmt.Errorf("line %d: rating before the first \"movie:\" line", lineNo)
+		}
+
+		fields := strings.Split(line, ",")
+		if len(fields) != 3 {
+			return fmt.Errorf("line %d: expected customer,rating,date, got %q", lineNo, line)
+		}
+
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid rating %q", lineNo, fields[1])
+		}
+		date, err := time.Parse("2006-01-02", fields[2])
+		if err != nil {
+			return fmt.Errorf("line %d: invalid date %q", lineNo, fields[2])
+		}
+
+		d.AddAt(fields[0], movie, value, date)
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,126 @@
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+)
+
+// Triple is a "user movie rating" line from a test file such as test5.txt, using external IDs. A Value of 0
+// marks a (user, movie) pair whose rating is to be predicted.
+type Triple struct {
+	User  string
+	Item  string
+	Value float64
+}
+
+// LoadTriples reads "user movie rating" triples such as test5.txt from the named file
+func LoadTriples(path string) ([]Triple, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadTriples(file)
+}
+
+// ReadTriples reads whitespace separated "user movie rating" triples, one per line
+func ReadTriples(reader io.Reader) ([]Triple, error) {
+	var triples []Triple
+
+	scanner := bufio.NewScanner(reader)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected \"user movie rating\", got %q", lineNo, scanner.Text())
+		}
+
+		value, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rating %q", lineNo, fields[2])
+		}
+
+		triples = append(triples, Triple{User: fields[0], Item: fields[1], Value: value})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return triples, nil
+}
+
+// AddTriples adds the known (non-zero) ratings among the triples to the data set, and returns the pairs
+// of the triples whose ratings are to be predicted. New users and movies are given the next free indexes.
+func (d *Dataset) AddTriples(triples []Triple) []cf.Pair {
+	var queries []cf.Pair
+
+	for _, triple := range triples {
+		user, item := d.Users.Index(triple.User), d.Items.Index(triple.Item)
+		if triple.Value != 0 {
+			d.Ratings.Add(user, item, triple.Value)
+		} else {
+			queries = append(queries, cf.Pair{User: user, Item: item})
+		}
+	}
+
+	d.Ratings.Grow(d.Users.Len(), d.Items.Len())
+
+	return queries
+}
+
+// WritePredictions writes the predictions as "user movie rating" triples using the data set's external IDs,
+// the format of result5.txt, result10.txt and result20.txt
+func (d *Dataset) WritePredictions(writer io.Writer, predictions []cf.Prediction) error {
+	ratings := make([]cf.Rating, len(predictions))
+	for idx, prediction := range predictions {
+		ratings[idx] = cf.Rating{User: prediction.User, Item: prediction.Item, Value: prediction.Value}
+	}
+
+	return d.WriteTriples(writer, ratings)
+}
+
+// WriteTriples writes the ratings as "user movie rating" triples using the data set's external IDs, the
+// format of test5.txt. Ratings with a Value of 0 mark pairs whose ratings are to be predicted.
+func (d *Dataset) WriteTriples(writer io.Writer, ratings []cf.Rating) error {
+	buffered := bufio.NewWriter(writer)
+
+	for _, rating := range ratings {
+		value := strconv.FormatFloat(rating.Value, 'f', -1, 64)
+		_, err := fmt.Fprintf(buffered, "%s %s %s\n", d.Users.ID(rating.User), d.Items.ID(rating.Item), value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return buffered.Flush()
+}
+
+// Resolve returns the triples as ratings using the data set's indexes. Every user and movie in the triples
+// must already be in the data set.
+func (d *Dataset) Resolve(triples []Triple) ([]cf.Rating, error) {
+	ratings := make([]cf.Rating, len(triples))
+
+	for idx, triple := range triples {
+		user, ok := d.Users.Lookup(triple.User)
+		if !ok {
+			return nil, fmt.Errorf("unknown user %s", triple.User)
+		}
+		item, ok := d.Items.Lookup(triple.Item)
+		if !ok {
+			return nil, fmt.Errorf("unknown movie %s", triple.Item)
+		}
+		ratings[idx] = cf.Rating{User: user, Item: item, Value: triple.Value}
+	}
+
+	return ratings, nil
+}
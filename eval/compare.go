@@ -0,0 +1,167 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/split"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/stats"
+)
+
+// Comparison holds the per-user errors of several algorithms run on the same folds. Only the test ratings
+// every algorithm made a scored prediction for are used, so the algorithms' errors are over exactly the same
+// ratings and are paired; each user's errors are pooled over those ratings in every fold the user was tested
+// in. As in Evaluate, predictions that fell back on a default value are scored unless ExcludeFallbacks is set,
+// in which case a rating any algorithm fell back on is left out for all of them. Failed predictions are
+// always left out.
+type Comparison struct {
+	Algorithms []string
+	Users      []int
+	Errors     [][]Errors // Errors[algorithm][user] are the errors of Algorithms[algorithm] for Users[user]
+}
+
+// Compare fits every named algorithm on the training set of every fold, using newPredictor to create them,
+// and collects each user's errors on the fold's test ratings
+func Compare(ctx context.Context, algorithms []string, newPredictor func(name string) (cf.Predictor, error), folds []split.Fold, opts Options) (Comparison, error) {
+	if len(algorithms) < 2 {
+		return Comparison{}, fmt.Errorf("a comparison needs at least 2 algorithms, got %d", len(algorithms))
+	}
+
+	predictions := make([][][]cf.Prediction, len(algorithms)) // predictions[algorithm][fold][test rating]
+	for idx, name := range algorithms {
+		predictions[idx] = make([][]cf.Prediction, len(folds))
+
+		for foldIdx, fold := range folds {
+			predictor, err := newPredictor(name)
+			if err != nil {
+				return Comparison{}, err
+			}
+			if err := predictor.Fit(fold.Train); err != nil {
+				return Comparison{}, fmt.Errorf("%s, fold %d: %v", name, foldIdx+1, err)
+			}
+
+			foldPredictions, err := cf.PredictParallel(ctx, predictor, cf.Pairs(fold.Test), cf.BatchOptions{})
+			if err != nil {
+				return Comparison{}, err
+			}
+			cf.ApplyOutput(foldPredictions, opts.Output, opts.scale())
+			predictions[idx][foldIdx] = foldPredictions
+		}
+	}
+
+	byUser := make([]map[int]*accumulator, len(algorithms))
+	for idx := range algorithms {
+		byUser[idx] = map[int]*accumulator{}
+	}
+	for foldIdx, fold := range folds {
+		for ratingIdx, rating := range fold.Test {
+			inAll := true
+			for idx := range algorithms {
+				if !scored(predictions[idx][foldIdx][ratingIdx], opts) {
+					inAll = false
+					break
+				}
+			}
+			if !inAll {
+				continue
+			}
+
+			for idx := range algorithms {
+				if byUser[idx][rating.User] == nil {
+					byUser[idx][rating.User] = &accumulator{}
+				}
+				byUser[idx][rating.User].add(predictions[idx][foldIdx][ratingIdx].Value, rating.Value)
+			}
+		}
+	}
+
+	comparison := Comparison{Algorithms: algorithms}
+	for user := range byUser[0] {
+		comparison.Users = append(comparison.Users, user)
+	}
+	sort.Ints(comparison.Users)
+
+	comparison.Errors = make([][]Errors, len(algorithms))
+	for idx := range algorithms {
+		comparison.Errors[idx] = make([]Errors, len(comparison.Users))
+		for userIdx, user := range comparison.Users {
+			comparison.Errors[idx][userIdx] = byUser[idx][user].errors()
+		}
+	}
+
+	return comparison, nil
+}
+
+// Metric returns the named per-user metric, "rmse" or "mae", of the algorithm at index algorithm
+func (c Comparison) Metric(algorithm int, metric string) ([]float64, error) {
+	values := make([]float64, len(c.Users))
+	for userIdx, errors := range c.Errors[algorithm] {
+		switch metric {
+		case "rmse":
+			values[userIdx] = errors.RMSE
+		case "mae":
+			values[userIdx] = errors.MAE
+		default:
+			return nil, fmt.Errorf("unknown metric %q (available: rmse, mae)", metric)
+		}
+	}
+	return values, nil
+}
+
+// Significance is the outcome of the tests of whether one algorithm's per-user errors differ from another's
+type Significance struct {
+	TTest     stats.TTest
+	Wilcoxon  stats.WilcoxonTest
+	Bootstrap stats.Interval // Confidence interval of the mean difference in per-user error
+}
+
+// SignificanceOptions controls the bootstrap confidence interval
+type SignificanceOptions struct {
+	Resamples  int     // Number of bootstrap resamples; 0 means 10000
+	Confidence float64 // Confidence level of the interval; 0 means 0.95
+	Seed       int64
+}
+
+// Significance tests whether the per-user metric of algorithm a differs from that of algorithm b. Positive
+// differences mean a's errors are higher.
+func (c Comparison) Significance(a, b int, metric string, opts SignificanceOptions) (Significance, error) {
+	if opts.Resamples == 0 {
+		opts.Resamples = 10000
+	}
+	if opts.Confidence == 0 {
+		opts.Confidence = 0.95
+	}
+
+	valuesA, err := c.Metric(a, metric)
+	if err != nil {
+		return Significance{}, err
+	}
+	valuesB, err := c.Metric(b, metric)
+	if err != nil {
+		return Significance{}, err
+	}
+
+	var result Significance
+	if result.TTest, err = stats.PairedTTest(valuesA, valuesB); err != nil {
+		return Significance{}, err
+	}
+	if result.Wilcoxon, err = stats.WilcoxonSignedRank(valuesA, valuesB); err != nil {
+		return Significance{}, err
+	}
+	if result.Bootstrap, err = stats.BootstrapMeanDiff(valuesA, valuesB, opts.Resamples, opts.Confidence, opts.Seed); err != nil {
+		return Significance{}, err
+	}
+
+	return result, nil
+}
+
+// scored reports whether the prediction counts towards the error metrics, following the same rules as Evaluate
+func scored(prediction cf.Prediction, opts Options) bool {
+	if math.IsNaN(prediction.Value) || math.IsInf(prediction.Value, 0) {
+		return false
+	}
+	return prediction.OK || !opts.ExcludeFallbacks
+}
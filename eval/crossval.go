@@ -0,0 +1,117 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/split"
+)
+
+// Summary is the mean and sample standard deviation of a metric over several folds
+type Summary struct {
+	Mean float64
+	Std  float64
+}
+
+// Summarize returns the mean and sample standard deviation of the values. The deviation of a single value is 0.
+func Summarize(values []float64) Summary {
+	var sum float64 = 0
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+
+	var sumOfSqrdDeviations float64 = 0
+	for _, value := range values {
+		sumOfSqrdDeviations += (value - mean) * (value - mean)
+	}
+
+	std := 0.0
+	if len(values) > 1 {
+		std = math.Sqrt(sumOfSqrdDeviations / float64(len(values)-1))
+	}
+
+	return Summary{Mean: mean, Std: std}
+}
+
+// String formats the summary as mean ± std, or n/a if a fold had nothing scored
+func (s Summary) String() string {
+	if math.IsNaN(s.Mean) {
+		return "n/a"
+	}
+	return fmt.Sprintf("%f ± %f", s.Mean, s.Std)
+}
+
+// CrossValidation is the result of evaluating an algorithm on every fold of a split
+type CrossValidation struct {
+	Folds []Report // One report per fold, in the splitter's order
+
+	FitTimes     []time.Duration // How long fitting took on each fold
+	PredictTimes []time.Duration // How long predicting each fold's test ratings took
+
+	RMSE     Summary
+	MAE      Summary
+	NMAE     Summary
+	Coverage Summary
+}
+
+// CrossValidate fits a new predictor from newPredictor on the training set of every fold and evaluates it on
+// the fold's test ratings
+func CrossValidate(ctx context.Context, newPredictor func() (cf.Predictor, error), folds []split.Fold, opts Options) (CrossValidation, error) {
+	var result CrossValidation
+	if len(folds) == 0 {
+		return result, fmt.Errorf("no folds to evaluate")
+	}
+
+	for idx, fold := range folds {
+		predictor, err := newPredictor()
+		if err != nil {
+			return result, err
+		}
+
+		start := time.Now()
+		if err := predictor.Fit(fold.Train); err != nil {
+			return result, fmt.Errorf("fold %d: %v", idx+1, err)
+		}
+		fitted := time.Now()
+
+		predictions, err := cf.PredictParallel(ctx, predictor, cf.Pairs(fold.Test), cf.BatchOptions{})
+		if err != nil {
+			return result, err
+		}
+		result.FitTimes = append(result.FitTimes, fitted.Sub(start))
+		result.PredictTimes = append(result.PredictTimes, time.Since(fitted))
+
+		cf.ApplyOutput(predictions, opts.Output, opts.scale())
+		report, err := Evaluate(fold.Test, predictions, opts)
+		if err != nil {
+			return result, fmt.Errorf("fold %d: %v", idx+1, err)
+		}
+		result.Folds = append(result.Folds, report)
+	}
+
+	result.RMSE = summarizeFolds(result.Folds, func(r Report) float64 { return r.RMSE })
+	result.MAE = summarizeFolds(result.Folds, func(r Report) float64 { return r.MAE })
+	result.NMAE = summarizeFolds(result.Folds, func(r Report) float64 { return r.NMAE })
+	result.Coverage = summarizeFolds(result.Folds, func(r Report) float64 { return r.Coverage })
+
+	return result, nil
+}
+
+// String summarizes the cross-validation on one line
+func (c CrossValidation) String() string {
+	return fmt.Sprintf("RMSE: %v  MAE: %v  NMAE: %v  coverage: %.1f%% ± %.1f%% (%d folds)",
+		c.RMSE, c.MAE, c.NMAE, 100*c.Coverage.Mean, 100*c.Coverage.Std, len(c.Folds))
+}
+
+// summarizeFolds summarizes one metric over the fold reports
+func summarizeFolds(reports []Report, metric func(Report) float64) Summary {
+	values := make([]float64, len(reports))
+	for idx, report := range reports {
+		values[idx] = metric(report)
+	}
+	return Summarize(values)
+}
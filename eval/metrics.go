@@ -0,0 +1,233 @@
+// Package eval measures how well the predictions of a cf.Predictor match held-out ratings. It accounts for
+// every test pair: each is either predicted, fallen back on or failed, and coverage reports the proportion
+// that were predicted.
+package eval
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+)
+
+// Options controls which predictions count towards the error metrics
+type Options struct {
+	// Scale is the rating scale, used to normalize the MAE. The zero value means cf.DefaultScale.
+	Scale cf.Scale
+
+	// Output is how predictions are fitted to the scale before they are scored, by the functions in this
+	// package that make predictions themselves. Evaluate scores the predictions it is given as they are.
+	Output cf.OutputPolicy
+
+	// ExcludeFallbacks leaves the predictions a predictor fell back on (such as a user's average rating) out
+	// of the error metrics. By default every finite prediction is scored, as the project report's programs
+	// scored the average rating they fell back on, so that every algorithm is scored on the same test pairs;
+	// coverage reports how many of them were real predictions.
+	ExcludeFallbacks bool
+}
+
+// Errors are the error metrics over a set of scored predictions
+type Errors struct {
+	Count int     // Number of predictions scored
+	RMSE  float64 // Root mean squared error
+	MAE   float64 // Mean absolute error
+	Bias  float64 // Mean of predicted minus actual rating; positive when predictions are too high
+}
+
+// RatingErrors are the error metrics of the predictions for test ratings with one particular value
+type RatingErrors struct {
+	Rating float64
+	Errors
+}
+
+// Report is the result of evaluating predictions against the actual test ratings
+type Report struct {
+	Total     int // Number of test pairs
+	Predicted int // Pairs the predictor made a real prediction for
+	FellBack  int // Pairs the predictor fell back on a default value for
+	Failed    int // Pairs whose predicted value was NaN or infinite; these are never scored
+
+	Errors
+	NMAE     float64 // MAE divided by the range of the rating scale
+	Coverage float64 // Proportion of the test pairs that were predicted, from 0 to 1
+
+	ByRating []RatingErrors // Errors broken down by actual rating value, lowest first
+}
+
+// Evaluate scores the predictions against the actual ratings, which must be for the same pairs in the same order
+func Evaluate(actual []cf.Rating, predicted []cf.Prediction, opts Options) (Report, error) {
+	if len(actual) != len(predicted) {
+		return Report{}, fmt.Errorf("got %d predictions for %d test ratings", len(predicted), len(actual))
+	}
+
+	scale := opts.scale()
+	report := Report{Total: len(actual)}
+	var overall accumulator
+	byRating := map[float64]*accumulator{}
+
+	for idx, prediction := range predicted {
+		rating := actual[idx]
+		if prediction.User != rating.User || prediction.Item != rating.Item {
+			return Report{}, fmt.Errorf("prediction %d is for user %d, movie %d but the test rating is for user %d, movie %d",
+				idx, prediction.User, prediction.Item, rating.User, rating.Item)
+		}
+
+		switch {
+		case math.IsNaN(prediction.Value) || math.IsInf(prediction.Value, 0):
+			report.Failed++
+			continue
+		case prediction.OK:
+			report.Predicted++
+		default:
+			report.FellBack++
+			if opts.ExcludeFallbacks {
+				continue
+			}
+		}
+
+		overall.add(prediction.Value, rating.Value)
+		if byRating[rating.Value] == nil {
+			byRating[rating.Value] = &accumulator{}
+		}
+		byRating[rating.Value].add(prediction.Value, rating.Value)
+	}
+
+	report.Errors = overall.errors()
+	report.NMAE = report.MAE / (scale.Max - scale.Min)
+	if report.Total > 0 {
+		report.Coverage = float64(report.Predicted) / float64(report.Total)
+	}
+
+	for rating, errors := range byRating {
+		report.ByRating = append(report.ByRating, RatingErrors{Rating: rating, Errors: errors.errors()})
+	}
+	sort.Slice(report.ByRating, func(i, j int) bool { return report.ByRating[i].Rating < report.ByRating[j].Rating })
+
+	return report, nil
+}
+
+// EvaluateCommon scores several predictors' predictions for the same actual ratings, like Evaluate, but takes
+// the error metrics of every report over only the ratings all of them scored, so that they can be ranked
+// against each other. The counts and coverage are still over every test pair. It also returns the number of
+// ratings the metrics are over.
+func EvaluateCommon(actual []cf.Rating, predicted [][]cf.Prediction, opts Options) ([]Report, int, error) {
+	reports := make([]Report, len(predicted))
+	for idx, predictions := range predicted {
+		report, err := Evaluate(actual, predictions, opts)
+		if err != nil {
+			return nil, 0, err
+		}
+		reports[idx] = report
+	}
+
+	var common []int
+	for ratingIdx := range actual {
+		inAll := true
+		for _, predictions := range predicted {
+			if !scored(predictions[ratingIdx], opts) {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			common = append(common, ratingIdx)
+		}
+	}
+
+	commonActual := make([]cf.Rating, len(common))
+	for idx, ratingIdx := range common {
+		commonActual[idx] = actual[ratingIdx]
+	}
+	for idx, predictions := range predicted {
+		commonPredictions := make([]cf.Prediction, len(common))
+		for commonIdx, ratingIdx := range common {
+			commonPredictions[commonIdx] = predictions[ratingIdx]
+		}
+
+		commonReport, err := Evaluate(commonActual, commonPredictions, opts)
+		if err != nil {
+			return nil, 0, err
+		}
+		reports[idx].Errors = commonReport.Errors
+		reports[idx].NMAE = commonReport.NMAE
+		reports[idx].ByRating = commonReport.ByRating
+	}
+
+	return reports, len(common), nil
+}
+
+// Run fits the predictor on the ratings other than the test ratings, predicts every test rating and
+// evaluates the predictions
+func Run(p cf.Predictor, ratings *cf.Ratings, test []cf.Rating, opts Options) (Report, error) {
+	if err := p.Fit(ratings.Without(test)); err != nil {
+		return Report{}, err
+	}
+
+	predictions := p.PredictBatch(cf.Pairs(test))
+	cf.ApplyOutput(predictions, opts.Output, opts.scale())
+	return Evaluate(test, predictions, opts)
+}
+
+// scale returns the rating scale, defaulting to cf.DefaultScale
+func (opts Options) scale() cf.Scale {
+	if opts.Scale == (cf.Scale{}) {
+		return cf.DefaultScale
+	}
+	return opts.Scale
+}
+
+// String summarizes the report on one line. Metrics are n/a when no prediction was scored.
+func (r Report) String() string {
+	return fmt.Sprintf("RMSE: %s  MAE: %s  NMAE: %s  coverage: %.1f%% (%d test ratings, %d fell back, %d failed)",
+		formatMetric(r.RMSE), formatMetric(r.MAE), formatMetric(r.NMAE), 100*r.Coverage, r.Total, r.FellBack, r.Failed)
+}
+
+// formatMetric formats an error metric, or returns n/a if it is NaN because nothing was scored
+func formatMetric(value float64) string {
+	if math.IsNaN(value) {
+		return "n/a"
+	}
+	return fmt.Sprintf("%f", value)
+}
+
+// WriteByRating writes the per-rating error breakdown as a table
+func (r Report) WriteByRating(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%8s %8s %10s %10s %10s\n", "rating", "count", "RMSE", "MAE", "bias"); err != nil {
+		return err
+	}
+	for _, row := range r.ByRating {
+		if _, err := fmt.Fprintf(w, "%8g %8d %10f %10f %+10f\n", row.Rating, row.Count, row.RMSE, row.MAE, row.Bias); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accumulator sums the errors of predictions one at a time
+type accumulator struct {
+	count           int
+	sumOfErrors     float64
+	sumOfAbsErrors  float64
+	sumOfSqrdErrors float64
+}
+
+func (a *accumulator) add(predicted, actual float64) {
+	difference := predicted - actual
+	a.count++
+	a.sumOfErrors += difference
+	a.sumOfAbsErrors += math.Abs(difference)
+	a.sumOfSqrdErrors += difference * difference
+}
+
+// errors returns the metrics of the predictions added so far; they are NaN if there were none
+func (a *accumulator) errors() Errors {
+	count := float64(a.count)
+	return Errors{
+		Count: a.count,
+		RMSE:  math.Sqrt(a.sumOfSqrdErrors / count),
+		MAE:   a.sumOfAbsErrors / count,
+		Bias:  a.sumOfErrors / count,
+	}
+}
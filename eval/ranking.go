@@ -0,0 +1,151 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+)
+
+// DefaultK is the length of the recommendation lists ranking metrics are computed over by default
+const DefaultK = 10
+
+// DefaultRelevanceThreshold is the lowest held-out rating, on the 1 to 5 scale, that counts as relevant by default
+const DefaultRelevanceThreshold = 4
+
+// RankingOptions controls how recommendation lists are scored
+type RankingOptions struct {
+	// K is the length of the recommendation list precision, recall, NDCG and hit rate look at; 0 means DefaultK
+	K int
+
+	// Threshold is the lowest held-out rating that makes a movie relevant to a user; 0 means
+	// DefaultRelevanceThreshold
+	Threshold float64
+}
+
+// RankingReport holds the ranking metrics averaged over every user that has at least one relevant held-out movie
+type RankingReport struct {
+	K       int
+	Users   int // Users the metrics are averaged over
+	Skipped int // Users with held-out ratings but none of them relevant
+
+	Precision float64 // Proportion of the top K that is relevant
+	Recall    float64 // Proportion of the relevant movies that are in the top K
+	NDCG      float64 // Discounted cumulative gain of the top K, normalized by that of a perfect ranking
+	MAP       float64 // Mean over users of the average precision at each relevant movie in the full ranking
+	MRR       float64 // Mean over users of 1 / the rank of the first relevant movie in the full ranking
+	HitRate   float64 // Proportion of users with at least one relevant movie in the top K
+}
+
+// EvaluateRanking ranks every movie each user in test has not rated in train with the predictor, which must
+// already be fitted on train, and scores the rankings against the user's relevant held-out movies. The test
+// ratings must not be in train. Movies the predictor can only fall back on a default for are not ranked.
+func EvaluateRanking(p cf.Predictor, train *cf.Ratings, test []cf.Rating, opts RankingOptions) (RankingReport, error) {
+	k := opts.K
+	if k == 0 {
+		k = DefaultK
+	}
+	threshold := opts.Threshold
+	if threshold == 0 {
+		threshold = DefaultRelevanceThreshold
+	}
+	if k < 0 {
+		return RankingReport{}, fmt.Errorf("list length must be positive, got %d", k)
+	}
+
+	relevant := map[int]map[int]bool{} // Relevant held-out movies, by user
+	for _, rating := range test {
+		if train.Has(rating.User, rating.Item) {
+			return RankingReport{}, fmt.Errorf("held-out rating of movie %d by user %d is also in the training set", rating.Item, rating.User)
+		}
+		if relevant[rating.User] == nil {
+			relevant[rating.User] = map[int]bool{}
+		}
+		if rating.Value >= threshold {
+			relevant[rating.User][rating.Item] = true
+		}
+	}
+
+	users := make([]int, 0, len(relevant))
+	for user := range relevant {
+		users = append(users, user)
+	}
+	sort.Ints(users)
+
+	report := RankingReport{K: k}
+	for _, user := range users {
+		if len(relevant[user]) == 0 {
+			report.Skipped++
+			continue
+		}
+
+		ranking := cf.Recommend(p, train, user, train.NumItems())
+		metrics := rankingMetrics(ranking, relevant[user], k)
+
+		report.Users++
+		report.Precision += metrics.Precision
+		report.Recall += metrics.Recall
+		report.NDCG += metrics.NDCG
+		report.MAP += metrics.MAP
+		report.MRR += metrics.MRR
+		report.HitRate += metrics.HitRate
+	}
+
+	if report.Users > 0 {
+		users := float64(report.Users)
+		report.Precision /= users
+		report.Recall /= users
+		report.NDCG /= users
+		report.MAP /= users
+		report.MRR /= users
+		report.HitRate /= users
+	}
+
+	return report, nil
+}
+
+// rankingMetrics scores a single user's ranking against the movies relevant to them
+func rankingMetrics(ranking []cf.Prediction, relevant map[int]bool, k int) RankingReport {
+	var metrics RankingReport
+	var hits int                    // Relevant movies seen so far in the ranking
+	var topHits int                 // Relevant movies in the top K
+	var dcg, idcg float64           // Discounted cumulative gain of the top K, and of a perfect top K
+	var sumOfPrecisions float64 = 0 // Represents: summation( precision at the rank of each relevant movie )
+
+	for rank, prediction := range ranking {
+		if !relevant[prediction.Item] {
+			continue
+		}
+
+		hits++
+		sumOfPrecisions += float64(hits) / float64(rank+1)
+		if hits == 1 {
+			metrics.MRR = 1 / float64(rank+1)
+		}
+		if rank < k {
+			topHits++
+			dcg += 1 / math.Log2(float64(rank+2))
+		}
+	}
+
+	for rank := 0; rank < min(k, len(relevant)); rank++ {
+		idcg += 1 / math.Log2(float64(rank+2))
+	}
+
+	metrics.Precision = float64(topHits) / float64(k)
+	metrics.Recall = float64(topHits) / float64(len(relevant))
+	metrics.NDCG = dcg / idcg
+	metrics.MAP = sumOfPrecisions / float64(len(relevant))
+	if topHits > 0 {
+		metrics.HitRate = 1
+	}
+
+	return metrics
+}
+
+// String summarizes the report on one line
+func (r RankingReport) String() string {
+	return fmt.Sprintf("precision@%d: %f  recall@%d: %f  NDCG@%d: %f  MAP: %f  MRR: %f  hit rate@%d: %f (%d users)",
+		r.K, r.Precision, r.K, r.Recall, r.K, r.NDCG, r.MAP, r.MRR, r.K, r.HitRate, r.Users)
+}
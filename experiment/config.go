@@ -0,0 +1,164 @@
+// Package experiment describes evaluation runs in configuration files and records every run in a manifest,
+// so that the numbers in a report can be traced back to, and reproduced from, exactly what produced them.
+package experiment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/dataset"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/split"
+)
+
+// Config describes an experiment: the data set, how to split it, the algorithms to evaluate and how to score
+// them. Configs are stored as JSON.
+type Config struct {
+	Name   string `json:"name,omitempty"`
+	Data   string `json:"data"`             // Path of the data set, relative to the working directory
+	Format string `json:"format,omitempty"` // Format of the data set; detected from the path if empty
+
+	// Implicit, if set, turns the data set's ratings into implicit interactions before it is split
+	Implicit *dataset.ImplicitOptions `json:"implicit,omitempty"`
+
+	Split      SplitConfig       `json:"split"`
+	Algorithms []AlgorithmConfig `json:"algorithms"`
+
+	Output           string `json:"output,omitempty"` // Output policy: raw, clamp or round; clamp if empty
+	Scale            string `json:"scale,omitempty"`  // Rating scale as min:max[:step]; 1:5:1 if empty
+	ExcludeFallbacks bool   `json:"exclude_fallbacks,omitempty"`
+}
+
+// AlgorithmConfig is an algorithm to evaluate, by its name in package cf, with the parameters to override
+type AlgorithmConfig struct {
+	Name   string    `json:"name"`
+	Params cf.Params `json:"params,omitempty"`
+}
+
+// SplitConfig describes how the data set is split into folds. Which fields are used depends on the method.
+type SplitConfig struct {
+	Method string `json:"method"` // One of SplitMethods()
+	Seed   int64  `json:"seed"`   // Seed for the random methods
+
+	Folds         int     `json:"folds,omitempty"`           // kfold and user-kfold
+	N             int     `json:"n,omitempty"`               // leave-n-out, last-n and given-n
+	Fraction      float64 `json:"fraction,omitempty"`        // holdout and user-kfold
+	FirstTestUser int     `json:"first_test_user,omitempty"` // given-n
+	Cutoff        string  `json:"cutoff,omitempty"`          // cutoff: YYYY-MM-DD date or Unix time
+	Start         string  `json:"start,omitempty"`           // sliding: YYYY-MM-DD date or Unix time
+	WindowDays    int     `json:"window_days,omitempty"`     // sliding
+	Windows       int     `json:"windows,omitempty"`         // sliding
+	HistoryDays   int     `json:"history_days,omitempty"`    // sliding; 0 trains on all earlier ratings
+}
+
+// splitMethods are the split methods a SplitConfig can name
+var splitMethods = []string{"kfold", "user-kfold", "leave-n-out", "holdout", "given-n", "cutoff", "last-n", "sliding"}
+
+// SplitMethods returns the name of every split method
+func SplitMethods() []string {
+	return append([]string(nil), splitMethods...)
+}
+
+// Splitter returns the splitter the config describes. times may be nil for data sets without timestamps,
+// in which case the chronological methods fail when they are used.
+func (s SplitConfig) Splitter(times *cf.Timestamps) (split.Splitter, error) {
+	day := 24 * time.Hour
+
+	switch s.Method {
+	case "kfold":
+		return split.KFold{K: s.Folds, Seed: s.Seed}, nil
+	case "user-kfold":
+		return split.UserKFold{K: s.Folds, HoldOut: s.Fraction, Seed: s.Seed}, nil
+	case "leave-n-out":
+		return split.LeaveNOut{N: s.N, Seed: s.Seed}, nil
+	case "holdout":
+		return split.HoldOut{Fraction: s.Fraction, Seed: s.Seed}, nil
+	case "given-n":
+		return split.GivenN{N: s.N, FirstTestUser: s.FirstTestUser, Seed: s.Seed}, nil
+	case "cutoff":
+		at, err := ParseTime(s.Cutoff)
+		return split.Cutoff{Times: times, At: at}, err
+	case "last-n":
+		return split.LastN{Times: times, N: s.N}, nil
+	case "sliding":
+		start, err := ParseTime(s.Start)
+		return split.SlidingWindow{Times: times, Start: start, Window: time.Duration(s.WindowDays) * day,
+			Windows: s.Windows, History: time.Duration(s.HistoryDays) * day}, err
+	default:
+		return nil, fmt.Errorf("unknown split %q (available: %s)", s.Method, strings.Join(splitMethods, ", "))
+	}
+}
+
+// ParseTime parses a YYYY-MM-DD date or a Unix time in seconds
+func ParseTime(value string) (time.Time, error) {
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unix, 0).UTC(), nil
+	}
+	when, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a YYYY-MM-DD date or a Unix time, got %q", value)
+	}
+	return when, nil
+}
+
+// LoadConfig reads a JSON config file. Unknown fields are an error, so that misspelled settings are not
+// silently ignored.
+func LoadConfig(path string) (Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer file.Close()
+
+	var config Config
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return Config{}, fmt.Errorf("reading %s: %v", path, err)
+	}
+
+	return config, config.validate()
+}
+
+// validate checks the settings that can be checked without loading the data set
+func (c Config) validate() error {
+	if c.Data == "" {
+		return fmt.Errorf("config has no data set")
+	}
+	if len(c.Algorithms) == 0 {
+		return fmt.Errorf("config has no algorithms")
+	}
+	for _, algorithm := range c.Algorithms {
+		if _, err := cf.New(algorithm.Name, algorithm.Params); err != nil {
+			return err
+		}
+	}
+	if _, err := c.outputPolicy(); err != nil {
+		return err
+	}
+	if _, err := c.scale(); err != nil {
+		return err
+	}
+	_, err := c.Split.Splitter(nil)
+	return err
+}
+
+// outputPolicy returns the configured output policy, defaulting to clamp
+func (c Config) outputPolicy() (cf.OutputPolicy, error) {
+	if c.Output == "" {
+		return cf.OutputClamp, nil
+	}
+	return cf.ParseOutputPolicy(c.Output)
+}
+
+// scale returns the configured rating scale, defaulting to cf.DefaultScale
+func (c Config) scale() (cf.Scale, error) {
+	if c.Scale == "" {
+		return cf.DefaultScale, nil
+	}
+	return cf.ParseScale(c.Scale)
+}
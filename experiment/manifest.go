@@ -0,0 +1,315 @@
+package experiment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/dataset"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/eval"
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/split"
+)
+
+// Manifest records everything about a run of an experiment that is needed to reproduce its results
+type Manifest struct {
+	Config Config `json:"config"`
+
+	Dataset     DatasetInfo `json:"dataset"`
+	GitRevision string      `json:"git_revision,omitempty"` // Revision of the code; "-dirty" is appended if it had local changes
+	GoVersion   string      `json:"go_version"`
+
+	Started time.Time `json:"started"`
+	Seconds Number    `json:"seconds"` // How long the whole run took
+
+	Search  *SearchOptions `json:"search,omitempty"` // How the algorithms were chosen, if by a hyperparameter search
+	Results []Result       `json:"results"`          // Best first when the run was a search
+}
+
+// DatasetInfo identifies the exact data set a run used
+type DatasetInfo struct {
+	Path    string `json:"path"`
+	Format  string `json:"format"`
+	SHA256  string `json:"sha256"` // Of the file, or of every file in the directory in name order
+	Ratings int    `json:"ratings"`
+	Users   int    `json:"users"`
+	Items   int    `json:"items"`
+}
+
+// Result holds the metrics of one algorithm across every fold
+type Result struct {
+	Algorithm string    `json:"algorithm"`
+	Params    cf.Params `json:"params,omitempty"`
+
+	RMSE     Summary `json:"rmse"`
+	MAE      Summary `json:"mae"`
+	NMAE     Summary `json:"nmae"`
+	Coverage Summary `json:"coverage"`
+
+	Folds []FoldResult `json:"folds"`
+}
+
+// Summary is the mean and standard deviation of a metric over the folds
+type Summary struct {
+	Mean Number `json:"mean"`
+	Std  Number `json:"std"`
+}
+
+// FoldResult holds the metrics and timings of one algorithm on one fold
+type FoldResult struct {
+	Total     int `json:"total"`
+	Predicted int `json:"predicted"`
+	FellBack  int `json:"fell_back"`
+	Failed    int `json:"failed"`
+
+	RMSE     Number `json:"rmse"`
+	MAE      Number `json:"mae"`
+	NMAE     Number `json:"nmae"`
+	Coverage Number `json:"coverage"`
+
+	FitSeconds     Number `json:"fit_seconds"`
+	PredictSeconds Number `json:"predict_seconds"`
+}
+
+// Number is a metric that is written to JSON as null when it is NaN or infinite, which JSON cannot represent
+type Number float64
+
+// MarshalJSON implements json.Marshaler
+func (n Number) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(n)) || math.IsInf(float64(n), 0) {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.FormatFloat(float64(n), 'g', -1, 64)), nil
+}
+
+// Run carries out the experiment and returns its manifest. progress, if not nil, is called with every
+// algorithm's result as soon as it is known.
+func Run(ctx context.Context, config Config, progress func(Result)) (*Manifest, error) {
+	manifest, folds, opts, err := start(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifest.Results, err = evaluate(ctx, config.Algorithms, folds, opts, progress); err != nil {
+		return nil, err
+	}
+
+	manifest.Seconds = Number(time.Since(manifest.Started).Seconds())
+	return manifest, nil
+}
+
+// start validates the config, loads and splits its data set and returns the manifest of a run that has
+// just started, along with the folds and evaluation options to run it with
+func start(config Config) (*Manifest, []split.Fold, eval.Options, error) {
+	if err := config.validate(); err != nil {
+		return nil, nil, eval.Options{}, err
+	}
+
+	manifest := &Manifest{
+		Config:      config,
+		GitRevision: gitRevision(),
+		GoVersion:   runtime.Version(),
+		Started:     time.Now().UTC(),
+	}
+
+	format := config.Format
+	if format == "" {
+		var err error
+		if format, err = dataset.DetectFormat(config.Data); err != nil {
+			return nil, nil, eval.Options{}, err
+		}
+	}
+	data, err := dataset.Load(config.Data, format)
+	if err != nil {
+		return nil, nil, eval.Options{}, err
+	}
+	if config.Implicit != nil {
+		data = data.Implicit(*config.Implicit)
+	}
+	checksum, err := checksum(config.Data)
+	if err != nil {
+		return nil, nil, eval.Options{}, err
+	}
+	manifest.Dataset = DatasetInfo{
+		Path:    config.Data,
+		Format:  format,
+		SHA256:  checksum,
+		Ratings: data.Ratings.Len(),
+		Users:   data.Ratings.NumUsers(),
+		Items:   data.Ratings.NumItems(),
+	}
+
+	splitter, err := config.Split.Splitter(data.Times)
+	if err != nil {
+		return nil, nil, eval.Options{}, err
+	}
+	folds, err := splitter.Split(data.Ratings)
+	if err != nil {
+		return nil, nil, eval.Options{}, err
+	}
+
+	policy, _ := config.outputPolicy()
+	scale, _ := config.scale()
+	return manifest, folds, eval.Options{Scale: scale, Output: policy, ExcludeFallbacks: config.ExcludeFallbacks}, nil
+}
+
+// evaluate cross-validates every algorithm on the folds
+func evaluate(ctx context.Context, algorithms []AlgorithmConfig, folds []split.Fold, opts eval.Options, progress func(Result)) ([]Result, error) {
+	results := make([]Result, 0, len(algorithms))
+	for _, algorithm := range algorithms {
+		algorithm := algorithm
+		newPredictor := func() (cf.Predictor, error) { return cf.New(algorithm.Name, algorithm.Params) }
+		validation, err := eval.CrossValidate(ctx, newPredictor, folds, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		result := newResult(algorithm, validation)
+		results = append(results, result)
+		if progress != nil {
+			progress(result)
+		}
+	}
+	return results, nil
+}
+
+// newResult converts a cross-validation into a manifest result
+func newResult(algorithm AlgorithmConfig, validation eval.CrossValidation) Result {
+	summary := func(s eval.Summary) Summary { return Summary{Mean: Number(s.Mean), Std: Number(s.Std)} }
+
+	result := Result{
+		Algorithm: algorithm.Name,
+		Params:    algorithm.Params,
+		RMSE:      summary(validation.RMSE),
+		MAE:       summary(validation.MAE),
+		NMAE:      summary(validation.NMAE),
+		Coverage:  summary(validation.Coverage),
+	}
+
+	for idx, report := range validation.Folds {
+		result.Folds = append(result.Folds, FoldResult{
+			Total:          report.Total,
+			Predicted:      report.Predicted,
+			FellBack:       report.FellBack,
+			Failed:         report.Failed,
+			RMSE:           Number(report.RMSE),
+			MAE:            Number(report.MAE),
+			NMAE:           Number(report.NMAE),
+			Coverage:       Number(report.Coverage),
+			FitSeconds:     Number(validation.FitTimes[idx].Seconds()),
+			PredictSeconds: Number(validation.PredictTimes[idx].Seconds()),
+		})
+	}
+
+	return result
+}
+
+// Write writes the manifest as indented JSON
+func (m *Manifest) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(m)
+}
+
+// WriteFile writes the manifest to the named file
+func (m *Manifest) WriteFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := m.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// checksum returns the SHA-256 of the named file, or of the names and contents of every file in the named
+// directory in name order
+func checksum(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	paths := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return "", err
+		}
+		paths = paths[:0]
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				paths = append(paths, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(paths)
+	}
+
+	hash := sha256.New()
+	for _, filePath := range paths {
+		if info.IsDir() {
+			io.WriteString(hash, filepath.Base(filePath)+"\n")
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(hash, file)
+		file.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// gitRevision returns the revision of the code that is running, from the build information when the binary
+// was built from a git checkout, or else from git itself. It returns "" if neither knows.
+func gitRevision() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		revision, modified := "", false
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if revision != "" {
+			if modified {
+				revision += "-dirty"
+			}
+			return revision
+		}
+	}
+
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	revision := strings.TrimSpace(string(out))
+
+	status, err := exec.Command("git", "status", "--porcelain", "--untracked-files=no").Output()
+	if err == nil && strings.TrimSpace(string(status)) != "" {
+		revision += "-dirty"
+	}
+	return revision
+}
@@ -0,0 +1,160 @@
+package experiment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+)
+
+// SearchOptions controls a hyperparameter search
+type SearchOptions struct {
+	Method string `json:"method"`           // "grid" tries every combination; "random" samples Trials of them
+	Trials int    `json:"trials,omitempty"` // Number of random combinations per algorithm
+	Seed   int64  `json:"seed"`             // Seed for random search
+	Metric string `json:"metric"`           // The metric to rank by, lowest first: "rmse", "mae" or "nmae"
+
+	// MinCoverage ranks the combinations whose mean coverage is below it after every other, so that none
+	// can win by declining to make predictions. Coverage is a proportion from 0 to 1.
+	MinCoverage float64 `json:"min_coverage,omitempty"`
+}
+
+// Search tunes every algorithm in the config over its declared hyperparameter space (see cf.SpaceOf),
+// cross-validating each combination on the config's split. Parameters an algorithm sets in the config are
+// held fixed and left out of the search. The manifest's results are the leaderboard, best first; see
+// BestConfig. progress, if not nil, is called with every combination's result as soon as it is known.
+func Search(ctx context.Context, config Config, opts SearchOptions, progress func(Result)) (*Manifest, error) {
+	metric, err := rankingMetric(opts.Metric)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MinCoverage < 0 || opts.MinCoverage > 1 {
+		return nil, fmt.Errorf("minimum coverage must be between 0 and 1, got %v", opts.MinCoverage)
+	}
+	candidates, err := opts.candidates(config.Algorithms)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, folds, evalOpts, err := start(config)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Search = &opts
+
+	if manifest.Results, err = evaluate(ctx, candidates, folds, evalOpts, progress); err != nil {
+		return nil, err
+	}
+
+	// Results below the minimum coverage come after the rest, and results without a metric, such as those
+	// of algorithms that predicted nothing, come last. Ties go to the higher coverage and then keep the order
+	// the combinations were tried in.
+	sort.SliceStable(manifest.Results, func(i, j int) bool {
+		resultI, resultJ := manifest.Results[i], manifest.Results[j]
+		coveredI := float64(resultI.Coverage.Mean) >= opts.MinCoverage
+		coveredJ := float64(resultJ.Coverage.Mean) >= opts.MinCoverage
+		if coveredI != coveredJ {
+			return coveredI
+		}
+
+		a, b := float64(metric(resultI).Mean), float64(metric(resultJ).Mean)
+		if math.IsNaN(a) || math.IsNaN(b) {
+			return math.IsNaN(b) && !math.IsNaN(a)
+		}
+		if a != b {
+			return a < b
+		}
+		return resultI.Coverage.Mean > resultJ.Coverage.Mean
+	})
+
+	manifest.Seconds = Number(time.Since(manifest.Started).Seconds())
+	return manifest, nil
+}
+
+// candidates returns the combinations of parameters to try for every algorithm
+func (opts SearchOptions) candidates(algorithms []AlgorithmConfig) ([]AlgorithmConfig, error) {
+	random := rand.New(rand.NewSource(opts.Seed))
+
+	var candidates []AlgorithmConfig
+	for _, algorithm := range algorithms {
+		space, err := cf.SpaceOf(algorithm.Name)
+		if err != nil {
+			return nil, err
+		}
+		space = space.Without(algorithm.Params.Names()...)
+
+		var points []cf.Params
+		switch opts.Method {
+		case "grid":
+			points = space.Grid()
+		case "random":
+			if opts.Trials < 1 {
+				return nil, fmt.Errorf("random search needs at least 1 trial, got %d", opts.Trials)
+			}
+			// Spaces of only a few discrete values may have fewer distinct points than trials
+			tried := map[string]bool{}
+			for attempt := 0; len(points) < opts.Trials && attempt < 10*opts.Trials; attempt++ {
+				point := space.Sample(random)
+				if !tried[point.Format()] {
+					tried[point.Format()] = true
+					points = append(points, point)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown search method %q (available: grid, random)", opts.Method)
+		}
+
+		for _, point := range points {
+			for name, value := range algorithm.Params {
+				point[name] = value
+			}
+			candidates = append(candidates, AlgorithmConfig{Name: algorithm.Name, Params: point})
+		}
+	}
+
+	return candidates, nil
+}
+
+// rankingMetric returns the function that picks the named metric out of a result
+func rankingMetric(name string) (func(Result) Summary, error) {
+	switch name {
+	case "rmse":
+		return func(r Result) Summary { return r.RMSE }, nil
+	case "mae":
+		return func(r Result) Summary { return r.MAE }, nil
+	case "nmae":
+		return func(r Result) Summary { return r.NMAE }, nil
+	default:
+		return nil, fmt.Errorf("unknown metric %q (available: rmse, mae, nmae)", name)
+	}
+}
+
+// BestConfig returns the config the search started from with its algorithms replaced by the best
+// combination on the leaderboard, ready to be run again with Run
+func (m *Manifest) BestConfig() (Config, error) {
+	if len(m.Results) == 0 {
+		return Config{}, fmt.Errorf("the manifest has no results")
+	}
+
+	best := m.Config
+	if best.Name != "" {
+		best.Name += "-best"
+	}
+	best.Algorithms = []AlgorithmConfig{{Name: m.Results[0].Algorithm, Params: m.Results[0].Params}}
+	return best, nil
+}
+
+// WriteFile writes the config as indented JSON to the named file, in the format LoadConfig reads
+func (c Config) WriteFile(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
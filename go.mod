@@ -0,0 +1,3 @@
+module github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation
+
+go 1.21
@@ -0,0 +1,38 @@
+package split
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+)
+
+// GivenN is the protocol of test5.txt, test10.txt and test20.txt: every test user keeps N of their ratings,
+// chosen at random, and the rest are held out to be predicted. The test users are those from FirstTestUser
+// onwards with more than N ratings; for train.txt, a FirstTestUser of 175 tests the last 25 users.
+type GivenN struct {
+	N             int
+	FirstTestUser int
+	Seed          int64
+}
+
+// Split implements Splitter, returning a single fold whose training set holds every rating of the training
+// users and only the N given ratings of each test user
+func (s GivenN) Split(ratings *cf.Ratings) ([]Fold, error) {
+	if s.N < 1 {
+		return nil, fmt.Errorf("given-n needs at least 1 given rating, got %d", s.N)
+	}
+	if s.FirstTestUser < 0 || s.FirstTestUser >= ratings.NumUsers() {
+		return nil, fmt.Errorf("first test user %d is out of range; there are %d users", s.FirstTestUser, ratings.NumUsers())
+	}
+
+	random := rand.New(rand.NewSource(s.Seed))
+	var test []cf.Rating
+	for user := s.FirstTestUser; user < ratings.NumUsers(); user++ {
+		if entries := ratings.UserRatings(user); len(entries) > s.N {
+			test = append(test, sample(random, user, entries, len(entries)-s.N)...)
+		}
+	}
+
+	return folds(ratings, [][]cf.Rating{test}), nil
+}
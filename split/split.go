@@ -0,0 +1,159 @@
+// Package split divides a rating store into training and test sets, so that algorithms can be evaluated on
+// ratings they were not fitted on. The random splitters are deterministic given their seed, and the
+// chronological splitters divide the ratings by when they were made.
+package split
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+)
+
+// Fold is one division of the ratings into a training set and the held-out test ratings. The test ratings
+// are not in the training set.
+type Fold struct {
+	Train *cf.Ratings
+	Test  []cf.Rating
+
+	// Cold holds the ratings the chronological splitters would have tested but set aside because their users
+	// have no training ratings, so no algorithm could do more than fall back for them
+	Cold []cf.Rating
+}
+
+// Splitter divides ratings into one or more folds
+type Splitter interface {
+	Split(ratings *cf.Ratings) ([]Fold, error)
+}
+
+// KFold splits the ratings into K equal parts at random; each fold tests on one part and trains on the rest
+type KFold struct {
+	K    int
+	Seed int64
+}
+
+// Split implements Splitter
+func (s KFold) Split(ratings *cf.Ratings) ([]Fold, error) {
+	if s.K < 2 {
+		return nil, fmt.Errorf("k-fold needs at least 2 folds, got %d", s.K)
+	}
+
+	all := ratings.All()
+	random := rand.New(rand.NewSource(s.Seed))
+	random.Shuffle(len(all), func(i, j int) { all[i], all[j] = all[j], all[i] })
+
+	parts := make([][]cf.Rating, s.K)
+	for idx, rating := range all {
+		parts[idx%s.K] = append(parts[idx%s.K], rating)
+	}
+
+	return folds(ratings, parts), nil
+}
+
+// UserKFold splits the users into K equal groups at random. Each fold tests on a random HoldOut proportion
+// of the ratings of one group of users, and trains on everything else, including their remaining ratings.
+type UserKFold struct {
+	K       int
+	HoldOut float64 // Proportion of each test user's ratings to hold out; 0 means 0.2
+	Seed    int64
+}
+
+// Split implements Splitter
+func (s UserKFold) Split(ratings *cf.Ratings) ([]Fold, error) {
+	if s.K < 2 {
+		return nil, fmt.Errorf("k-fold needs at least 2 folds, got %d", s.K)
+	}
+	holdOut := s.HoldOut
+	if holdOut == 0 {
+		holdOut = 0.2
+	}
+	if holdOut < 0 || holdOut >= 1 {
+		return nil, fmt.Errorf("hold-out proportion must be between 0 and 1, got %v", holdOut)
+	}
+
+	random := rand.New(rand.NewSource(s.Seed))
+	users := random.Perm(ratings.NumUsers())
+
+	parts := make([][]cf.Rating, s.K)
+	for idx, user := range users {
+		entries := ratings.UserRatings(user)
+		n := int(holdOut*float64(len(entries)) + 0.5)
+		parts[idx%s.K] = append(parts[idx%s.K], sample(random, user, entries, n)...)
+	}
+
+	return folds(ratings, parts), nil
+}
+
+// LeaveNOut holds out N random ratings of every user who has more than N, in a single fold
+type LeaveNOut struct {
+	N    int
+	Seed int64
+}
+
+// Split implements Splitter
+func (s LeaveNOut) Split(ratings *cf.Ratings) ([]Fold, error) {
+	if s.N < 1 {
+		return nil, fmt.Errorf("leave-n-out needs to leave out at least 1 rating, got %d", s.N)
+	}
+
+	random := rand.New(rand.NewSource(s.Seed))
+	var test []cf.Rating
+	for user := 0; user < ratings.NumUsers(); user++ {
+		if entries := ratings.UserRatings(user); len(entries) > s.N {
+			test = append(test, sample(random, user, entries, s.N)...)
+		}
+	}
+
+	return folds(ratings, [][]cf.Rating{test}), nil
+}
+
+// HoldOut holds out a random Fraction of all the ratings, in a single fold
+type HoldOut struct {
+	Fraction float64
+	Seed     int64
+}
+
+// Split implements Splitter
+func (s HoldOut) Split(ratings *cf.Ratings) ([]Fold, error) {
+	if s.Fraction <= 0 || s.Fraction >= 1 {
+		return nil, fmt.Errorf("hold-out fraction must be between 0 and 1, got %v", s.Fraction)
+	}
+
+	all := ratings.All()
+	random := rand.New(rand.NewSource(s.Seed))
+	random.Shuffle(len(all), func(i, j int) { all[i], all[j] = all[j], all[i] })
+
+	test := all[:int(s.Fraction*float64(len(all))+0.5)]
+	return folds(ratings, [][]cf.Rating{test}), nil
+}
+
+// sample returns n of the user's ratings chosen at random
+func sample(random *rand.Rand, user int, entries []cf.Entry, n int) []cf.Rating {
+	chosen := make([]cf.Rating, 0, n)
+	for _, idx := range random.Perm(len(entries))[:n] {
+		chosen = append(chosen, cf.Rating{User: user, Item: entries[idx].Index, Value: entries[idx].Value})
+	}
+	return chosen
+}
+
+// folds returns one fold per part, testing on the part and training on every other rating. The test ratings
+// of each fold are sorted by user and then movie.
+func folds(ratings *cf.Ratings, parts [][]cf.Rating) []Fold {
+	result := make([]Fold, len(parts))
+	for idx, test := range parts {
+		sortRatings(test)
+		result[idx] = Fold{Train: ratings.Without(test), Test: test}
+	}
+	return result
+}
+
+// sortRatings sorts the ratings by user and then movie
+func sortRatings(ratings []cf.Rating) {
+	sort.Slice(ratings, func(i, j int) bool {
+		if ratings[i].User != ratings[j].User {
+			return ratings[i].User < ratings[j].User
+		}
+		return ratings[i].Item < ratings[j].Item
+	})
+}
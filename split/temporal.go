@@ -0,0 +1,178 @@
+package split
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/beckettjohnson/Collaborative-Filtering-Algorithms-for-Movie-Recommendation/cf"
+)
+
+// Cutoff splits the ratings chronologically: ratings made before At are for training and the rest are tested,
+// as if the algorithm had been deployed at that moment. The later ratings of users who only rated from At
+// onwards are set aside in the fold's Cold ratings rather than tested.
+type Cutoff struct {
+	Times *cf.Timestamps
+	At    time.Time
+}
+
+// Split implements Splitter
+func (s Cutoff) Split(ratings *cf.Ratings) ([]Fold, error) {
+	timed, err := timedRatings(ratings, s.Times)
+	if err != nil {
+		return nil, err
+	}
+
+	var test []cf.Rating
+	for _, rating := range timed {
+		if !rating.when.Before(s.At) {
+			test = append(test, rating.Rating)
+		}
+	}
+	if len(test) == 0 || len(test) == len(timed) {
+		return nil, fmt.Errorf("cutoff %v leaves no ratings on one side of the split", s.At.Format(time.DateOnly))
+	}
+
+	fold := setAsideCold(folds(ratings, [][]cf.Rating{test})[0])
+	if len(fold.Test) == 0 {
+		return nil, fmt.Errorf("cutoff %v leaves no test ratings by users with training ratings", s.At.Format(time.DateOnly))
+	}
+	return []Fold{fold}, nil
+}
+
+// LastN holds out the N most recent ratings of every user who has more than N, in a single fold
+type LastN struct {
+	Times *cf.Timestamps
+	N     int
+}
+
+// Split implements Splitter
+func (s LastN) Split(ratings *cf.Ratings) ([]Fold, error) {
+	if s.N < 1 {
+		return nil, fmt.Errorf("last-n needs to hold out at least 1 rating, got %d", s.N)
+	}
+	if _, err := timedRatings(ratings, s.Times); err != nil {
+		return nil, err
+	}
+
+	var test []cf.Rating
+	for user := 0; user < ratings.NumUsers(); user++ {
+		entries := append([]cf.Entry(nil), ratings.UserRatings(user)...)
+		if len(entries) <= s.N {
+			continue
+		}
+
+		// Most recent first; ratings made at the same time are ordered by movie
+		sort.SliceStable(entries, func(i, j int) bool {
+			timeI, _ := s.Times.Get(user, entries[i].Index)
+			timeJ, _ := s.Times.Get(user, entries[j].Index)
+			if !timeI.Equal(timeJ) {
+				return timeI.After(timeJ)
+			}
+			return entries[i].Index < entries[j].Index
+		})
+
+		for _, entry := range entries[:s.N] {
+			test = append(test, cf.Rating{User: user, Item: entry.Index, Value: entry.Value})
+		}
+	}
+
+	return folds(ratings, [][]cf.Rating{test}), nil
+}
+
+// SlidingWindow is a backtest: it steps through Windows consecutive test windows of length Window, starting
+// at Start. Each fold tests on the ratings made in its window and trains only on ratings made before the
+// window began, so every fold retrains on the history that would have been available at that moment. The
+// ratings of users with no training ratings are set aside in each fold's Cold ratings rather than tested.
+type SlidingWindow struct {
+	Times   *cf.Timestamps
+	Start   time.Time
+	Window  time.Duration
+	Windows int
+
+	// History limits training to the ratings made in the History before each window; 0 trains on all of them
+	History time.Duration
+}
+
+// Split implements Splitter
+func (s SlidingWindow) Split(ratings *cf.Ratings) ([]Fold, error) {
+	if s.Window <= 0 || s.Windows < 1 {
+		return nil, fmt.Errorf("sliding window needs a positive window length and at least 1 window")
+	}
+	if s.History < 0 {
+		return nil, fmt.Errorf("training history must not be negative, got %v", s.History)
+	}
+	timed, err := timedRatings(ratings, s.Times)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Fold, 0, s.Windows)
+	for window := 0; window < s.Windows; window++ {
+		from := s.Start.Add(time.Duration(window) * s.Window)
+		to := from.Add(s.Window)
+
+		var test, excluded []cf.Rating // excluded holds every rating left out of training, including test
+		for _, rating := range timed {
+			inHistory := rating.when.Before(from) && (s.History == 0 || !rating.when.Before(from.Add(-s.History)))
+			if !inHistory {
+				excluded = append(excluded, rating.Rating)
+			}
+			if !rating.when.Before(from) && rating.when.Before(to) {
+				test = append(test, rating.Rating)
+			}
+		}
+		if len(test) == 0 {
+			return nil, fmt.Errorf("window %d (%v to %v) has no ratings", window+1, from.Format(time.DateOnly), to.Format(time.DateOnly))
+		}
+
+		sortRatings(test)
+		fold := setAsideCold(Fold{Train: ratings.Without(excluded), Test: test})
+		if len(fold.Test) == 0 {
+			return nil, fmt.Errorf("window %d (%v to %v) has no ratings by users with training ratings", window+1, from.Format(time.DateOnly), to.Format(time.DateOnly))
+		}
+		result = append(result, fold)
+	}
+
+	return result, nil
+}
+
+// setAsideCold moves the test ratings of users without any training ratings from the fold's Test to its Cold
+func setAsideCold(fold Fold) Fold {
+	var test, cold []cf.Rating
+	for _, rating := range fold.Test {
+		if len(fold.Train.UserRatings(rating.User)) == 0 {
+			cold = append(cold, rating)
+		} else {
+			test = append(test, rating)
+		}
+	}
+
+	fold.Test, fold.Cold = test, cold
+	return fold
+}
+
+// timedRating is a rating along with when it was made
+type timedRating struct {
+	cf.Rating
+	when time.Time
+}
+
+// timedRatings returns every rating with its timestamp, failing if any rating has none
+func timedRatings(ratings *cf.Ratings, times *cf.Timestamps) ([]timedRating, error) {
+	if times == nil {
+		return nil, fmt.Errorf("the ratings have no timestamps")
+	}
+
+	all := ratings.All()
+	timed := make([]timedRating, len(all))
+	for idx, rating := range all {
+		when, ok := times.Get(rating.User, rating.Item)
+		if !ok {
+			return nil, fmt.Errorf("the rating of movie %d by user %d has no timestamp", rating.Item, rating.User)
+		}
+		timed[idx] = timedRating{Rating: rating, when: when}
+	}
+
+	return timed, nil
+}
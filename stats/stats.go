@@ -0,0 +1,266 @@
+// Package stats implements the statistical tests used to decide whether one algorithm's errors are
+// significantly lower than another's. Every test is paired: a[i] and b[i] must be measurements of the same
+// unit, such as the same user, under the two algorithms.
+package stats
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// TTest is the result of a paired Student's t-test
+type TTest struct {
+	N        int     // Number of pairs
+	MeanDiff float64 // Mean of a[i] - b[i]
+	T        float64 // t statistic
+	DF       int     // Degrees of freedom
+	P        float64 // Two-sided p-value
+}
+
+// PairedTTest tests whether the mean difference between the paired samples is 0
+func PairedTTest(a, b []float64) (TTest, error) {
+	diffs, err := differences(a, b)
+	if err != nil {
+		return TTest{}, err
+	}
+	if len(diffs) < 2 {
+		return TTest{}, fmt.Errorf("a t-test needs at least 2 pairs, got %d", len(diffs))
+	}
+
+	n := float64(len(diffs))
+	mean := Mean(diffs)
+
+	var sumOfSqrdDeviations float64 = 0
+	for _, diff := range diffs {
+		sumOfSqrdDeviations += (diff - mean) * (diff - mean)
+	}
+	std := math.Sqrt(sumOfSqrdDeviations / (n - 1))
+
+	result := TTest{N: len(diffs), MeanDiff: mean, DF: len(diffs) - 1}
+	if std == 0 {
+		// Every difference is the same; the test is only meaningful if they are all 0
+		result.P = 1
+		if mean != 0 {
+			result.T = math.Copysign(math.Inf(1), mean)
+			result.P = 0
+		}
+		return result, nil
+	}
+
+	result.T = mean / (std / math.Sqrt(n))
+	df := float64(result.DF)
+	result.P = regularizedIncompleteBeta(df/(df+result.T*result.T), df/2, 0.5)
+
+	return result, nil
+}
+
+// WilcoxonTest is the result of a Wilcoxon signed-rank test
+type WilcoxonTest struct {
+	N     int     // Number of pairs with a non-zero difference; pairs that are equal are dropped
+	WPlus float64 // Sum of the ranks of the positive differences a[i] - b[i]
+	Z     float64 // Normal approximation of the statistic, with tie and continuity corrections
+	P     float64 // Two-sided p-value
+}
+
+// WilcoxonSignedRank tests whether the differences between the paired samples are symmetric around 0. It
+// makes no assumption that they are normally distributed. The p-value uses the normal approximation, which
+// is reasonable from about 20 non-zero differences.
+func WilcoxonSignedRank(a, b []float64) (WilcoxonTest, error) {
+	diffs, err := differences(a, b)
+	if err != nil {
+		return WilcoxonTest{}, err
+	}
+
+	var nonZero []float64
+	for _, diff := range diffs {
+		if diff != 0 {
+			nonZero = append(nonZero, diff)
+		}
+	}
+	result := WilcoxonTest{N: len(nonZero), P: 1}
+	if len(nonZero) == 0 {
+		return result, nil
+	}
+
+	sort.Slice(nonZero, func(i, j int) bool { return math.Abs(nonZero[i]) < math.Abs(nonZero[j]) })
+
+	// Rank the absolute differences, giving tied differences their average rank
+	var tieCorrection float64 = 0 // Represents: summation( cubed(Tie_Size) - Tie_Size )
+	for start := 0; start < len(nonZero); {
+		end := start
+		for end < len(nonZero) && math.Abs(nonZero[end]) == math.Abs(nonZero[start]) {
+			end++
+		}
+
+		rank := float64(start+end+1) / 2
+		for _, diff := range nonZero[start:end] {
+			if diff > 0 {
+				result.WPlus += rank
+			}
+		}
+
+		ties := float64(end - start)
+		tieCorrection += ties*ties*ties - ties
+		start = end
+	}
+
+	n := float64(len(nonZero))
+	mean := n * (n + 1) / 4
+	variance := n*(n+1)*(2*n+1)/24 - tieCorrection/48
+	if variance == 0 {
+		return result, nil
+	}
+
+	deviation := result.WPlus - mean
+	continuity := math.Copysign(math.Min(0.5, math.Abs(deviation)), deviation)
+	result.Z = (deviation - continuity) / math.Sqrt(variance)
+	result.P = math.Erfc(math.Abs(result.Z) / math.Sqrt2)
+
+	return result, nil
+}
+
+// Interval is a confidence interval
+type Interval struct {
+	Low, High float64
+}
+
+// String formats the interval as [low, high]
+func (i Interval) String() string {
+	return fmt.Sprintf("[%f, %f]", i.Low, i.High)
+}
+
+// BootstrapMeanDiff returns a percentile bootstrap confidence interval, at the given confidence level such as
+// 0.95, for the mean of a[i] - b[i]. It resamples the pairs with replacement the given number of times.
+func BootstrapMeanDiff(a, b []float64, resamples int, confidence float64, seed int64) (Interval, error) {
+	diffs, err := differences(a, b)
+	if err != nil {
+		return Interval{}, err
+	}
+	if len(diffs) == 0 {
+		return Interval{}, fmt.Errorf("the bootstrap needs at least 1 pair")
+	}
+	if resamples < 1 {
+		return Interval{}, fmt.Errorf("the bootstrap needs at least 1 resample, got %d", resamples)
+	}
+	if confidence <= 0 || confidence >= 1 {
+		return Interval{}, fmt.Errorf("confidence level must be between 0 and 1, got %v", confidence)
+	}
+
+	random := rand.New(rand.NewSource(seed))
+	means := make([]float64, resamples)
+	for resample := range means {
+		var sum float64 = 0
+		for range diffs {
+			sum += diffs[random.Intn(len(diffs))]
+		}
+		means[resample] = sum / float64(len(diffs))
+	}
+	sort.Float64s(means)
+
+	tail := (1 - confidence) / 2
+	return Interval{Low: quantile(means, tail), High: quantile(means, 1-tail)}, nil
+}
+
+// Mean returns the mean of the values, or NaN if there are none
+func Mean(values []float64) float64 {
+	var sum float64 = 0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// differences returns a[i] - b[i] for every pair
+func differences(a, b []float64) ([]float64, error) {
+	if len(a) != len(b) {
+		return nil, fmt.Errorf("paired samples must be the same length, got %d and %d", len(a), len(b))
+	}
+
+	diffs := make([]float64, len(a))
+	for idx := range a {
+		diffs[idx] = a[idx] - b[idx]
+	}
+	return diffs, nil
+}
+
+// quantile returns the q quantile of the sorted values, interpolating between neighbouring values
+func quantile(sorted []float64, q float64) float64 {
+	position := q * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	return sorted[lower] + (position-float64(lower))*(sorted[upper]-sorted[lower])
+}
+
+// regularizedIncompleteBeta returns I_x(a, b), evaluated with the continued fraction from Numerical Recipes
+func regularizedIncompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	lgammaA, _ := math.Lgamma(a)
+	lgammaB, _ := math.Lgamma(b)
+	lgammaAB, _ := math.Lgamma(a + b)
+	front := math.Exp(lgammaAB - lgammaA - lgammaB + a*math.Log(x) + b*math.Log(1-x))
+
+	// The continued fraction converges quickly for x below its mean; use the symmetry I_x(a, b) = 1 - I_1-x(b, a) otherwise
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaContinuedFraction(1-x, b, a)/b
+	}
+	return front * betaContinuedFraction(x, a, b) / a
+}
+
+// betaContinuedFraction evaluates the continued fraction for the incomplete beta function with Lentz's method
+func betaContinuedFraction(x, a, b float64) float64 {
+	const epsilon = 1e-14
+	const tiny = 1e-300
+
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	result := d
+
+	for m := 1; m <= 300; m++ {
+		fm := float64(m)
+
+		// Even step
+		numerator := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + numerator*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + numerator/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		result *= d * c
+
+		// Odd step
+		numerator = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + numerator*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + numerator/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		result *= delta
+
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+
+	return result
+}
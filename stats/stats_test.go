@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+// TestTDistributionPValue checks the two-sided p-values of Student's t distribution, which PairedTTest
+// computes with the regularized incomplete beta function, against reference values
+func TestTDistributionPValue(t *testing.T) {
+	tests := []struct {
+		t    float64
+		df   float64
+		want float64
+	}{
+		{t: 2.0, df: 1, want: 0.29516724}, // 1 - 2/π·atan(2)
+		{t: 2.0, df: 2, want: 0.18350342}, // 1 - 2/√6
+		{t: 2.0, df: 3, want: 0.13932597},
+		{t: 2.0, df: 10, want: 0.07338803},
+		{t: 1.83, df: 11, want: 0.09445599},
+		{t: 3.0, df: 20, want: 0.00707590},
+		{t: 0.5, df: 30, want: 0.62072300},
+		{t: 12.706, df: 1, want: 0.05}, // Critical values from the usual t tables
+		{t: 2.228, df: 10, want: 0.05},
+	}
+
+	for _, test := range tests {
+		got := regularizedIncompleteBeta(test.df/(test.df+test.t*test.t), test.df/2, 0.5)
+		if math.Abs(got-test.want) > 1e-4 {
+			t.Errorf("p-value for t = %v, df = %v: got %v, want %v", test.t, test.df, got, test.want)
+		}
+	}
+}
+
+// TestRegularizedIncompleteBeta checks identities of I_x(a, b) that hold for any x
+func TestRegularizedIncompleteBeta(t *testing.T) {
+	for _, x := range []float64{0.01, 0.2, 0.5, 0.8, 0.99} {
+		if got := regularizedIncompleteBeta(x, 1, 1); math.Abs(got-x) > 1e-10 {
+			t.Errorf("I_%v(1, 1): got %v, want %v", x, got, x)
+		}
+		if got, want := regularizedIncompleteBeta(x, 3, 1), x*x*x; math.Abs(got-want) > 1e-10 {
+			t.Errorf("I_%v(3, 1): got %v, want %v", x, got, want)
+		}
+		if got, want := regularizedIncompleteBeta(x, 2.5, 4)+regularizedIncompleteBeta(1-x, 4, 2.5), 1.0; math.Abs(got-want) > 1e-10 {
+			t.Errorf("I_%v(2.5, 4) + I_%v(4, 2.5): got %v, want %v", x, 1-x, got, want)
+		}
+	}
+	if got := regularizedIncompleteBeta(0.5, 7, 7); math.Abs(got-0.5) > 1e-10 {
+		t.Errorf("I_0.5(7, 7): got %v, want 0.5", got)
+	}
+}
+
+func TestPairedTTest(t *testing.T) {
+	// The differences are 1 to 5: mean 3, standard deviation √2.5, so t = 3 / (√2.5 / √5) = √18
+	result, err := PairedTTest([]float64{2, 4, 6, 8, 10}, []float64{1, 2, 3, 4, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.N != 5 || result.DF != 4 || result.MeanDiff != 3 {
+		t.Errorf("got n = %d, df = %d, mean difference %v; want 5, 4 and 3", result.N, result.DF, result.MeanDiff)
+	}
+	if math.Abs(result.T-math.Sqrt(18)) > 1e-10 {
+		t.Errorf("t: got %v, want %v", result.T, math.Sqrt(18))
+	}
+	if math.Abs(result.P-0.01323560) > 1e-6 {
+		t.Errorf("p: got %v, want 0.01323560", result.P)
+	}
+}
+
+func TestWilcoxonSignedRank(t *testing.T) {
+	// 20 distinct positive differences: W+ = 210, mean 105 and variance 717.5, so z = 104.5 / √717.5
+	a := make([]float64, 20)
+	b := make([]float64, 20)
+	for idx := range a {
+		a[idx] = float64(idx + 1)
+	}
+
+	result, err := WilcoxonSignedRank(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.N != 20 || result.WPlus != 210 {
+		t.Errorf("got n = %d, W+ = %v; want 20 and 210", result.N, result.WPlus)
+	}
+	if math.Abs(result.Z-3.90126398) > 1e-6 {
+		t.Errorf("z: got %v, want 3.90126398", result.Z)
+	}
+	if math.Abs(result.P-9.56917316e-5) > 1e-10 {
+		t.Errorf("p: got %v, want 9.56917316e-5", result.P)
+	}
+}
+
+func TestWilcoxonSignedRankTies(t *testing.T) {
+	// Every difference is ±1, so all 10 share the average rank 5.5; 6 positive ones give W+ = 33, and the tie
+	// correction leaves a variance of 96.25 - 990/48 = 75.625
+	a := []float64{1, 1, 1, 1, 1, 1, 0, 0, 0, 0}
+	b := []float64{0, 0, 0, 0, 0, 0, 1, 1, 1, 1}
+
+	result, err := WilcoxonSignedRank(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := (33 - 27.5 - 0.5) / math.Sqrt(75.625)
+	if result.WPlus != 33 || math.Abs(result.Z-want) > 1e-10 {
+		t.Errorf("got W+ = %v, z = %v; want 33 and %v", result.WPlus, result.Z, want)
+	}
+}
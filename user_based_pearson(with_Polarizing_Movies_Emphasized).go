@@ -1,3 +1,5 @@
+//go:build ignore
+
 /*
 Author: Beckett Johnson
 Date: 3/13/2021